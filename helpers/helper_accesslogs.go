@@ -0,0 +1,189 @@
+package helpers
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// Fields of a server access log record (https://docs.aws.amazon.com/AmazonS3/latest/userguide/LogFormat.html)
+const (
+	accessLogBucketField    = 1
+	accessLogTimeField      = 2
+	accessLogOperationField = 6
+)
+
+// ParseAccessLogs counts the requests of S3 server access log files, path being a log file or a
+// directory of log files. The counts are extrapolated to a month using the time span covered by
+// the logs (at least one day). The storage type of the requests is unknown.
+func ParseAccessLogs(path string) (BucketRequests, error) {
+	files, err := accessLogFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]RequestCounts{}
+	var firstRequest, lastRequest time.Time
+
+	for _, file := range files {
+		err := readAccessLogFile(file, func(bucket string, requestTime time.Time, tier int) {
+			switch tier {
+			case 1:
+				counts[bucket] = counts[bucket].Add(RequestCounts{Tier1: 1})
+			case 2:
+				counts[bucket] = counts[bucket].Add(RequestCounts{Tier2: 1})
+			}
+
+			if firstRequest.IsZero() || requestTime.Before(firstRequest) {
+				firstRequest = requestTime
+			}
+			if requestTime.After(lastRequest) {
+				lastRequest = requestTime
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", file, err)
+		}
+	}
+
+	span := max(lastRequest.Sub(firstRequest), 24*time.Hour)
+	monthRatio := float64(30*24*time.Hour) / float64(span)
+
+	requests := BucketRequests{}
+	for bucket, count := range counts {
+		requests[bucket] = map[string]RequestCounts{
+			"": {
+				Tier1: int(float64(count.Tier1) * monthRatio),
+				Tier2: int(float64(count.Tier2) * monthRatio),
+			},
+		}
+	}
+
+	return requests, nil
+}
+
+func accessLogFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+func readAccessLogFile(path string, onRequest func(bucket string, requestTime time.Time, tier int)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := splitAccessLogLine(scanner.Text())
+		if len(fields) <= accessLogOperationField {
+			continue
+		}
+
+		requestTime, err := time.Parse(accessLogTimeLayout, fields[accessLogTimeField])
+		if err != nil {
+			continue
+		}
+
+		onRequest(fields[accessLogBucketField], requestTime, RequestTier(fields[accessLogOperationField]))
+	}
+
+	return scanner.Err()
+}
+
+// splitAccessLogLine splits a record on spaces, keeping the [bracketed] and "quoted" fields whole
+func splitAccessLogLine(line string) []string {
+	fields := []string{}
+	for i := 0; i < len(line); {
+		var end int
+		switch line[i] {
+		case ' ':
+			i++
+			continue
+		case '[':
+			end = strings.IndexByte(line[i:], ']')
+			if end == -1 {
+				return append(fields, line[i+1:])
+			}
+			fields = append(fields, line[i+1:i+end])
+			i += end + 1
+		case '"':
+			end = strings.IndexByte(line[i+1:], '"')
+			if end == -1 {
+				return append(fields, line[i+1:])
+			}
+			fields = append(fields, line[i+1:i+1+end])
+			i += end + 2
+		default:
+			end = strings.IndexByte(line[i:], ' ')
+			if end == -1 {
+				return append(fields, line[i:])
+			}
+			fields = append(fields, line[i:i+end])
+			i += end
+		}
+	}
+	return fields
+}
+
+// RequestTier returns the billing tier of a logged operation such as REST.GET.OBJECT,
+// 0 for operations that are not billed as requests (deletes, lifecycle actions, ...)
+func RequestTier(operation string) int {
+	// Objects served by the static website endpoint are billed as GET requests
+	if operation == "WEBSITE.GET.OBJECT" {
+		return 2
+	}
+
+	parts := strings.SplitN(operation, ".", 3)
+	if len(parts) != 3 || parts[0] != "REST" {
+		return 0
+	}
+
+	method, resource := parts[1], parts[2]
+	switch method {
+	case "PUT", "POST":
+		return 1
+	case "COPY":
+		// The source side of a copy is logged separately but only the copy is billed
+		if resource == "OBJECT_GET" {
+			return 0
+		}
+		return 1
+	case "GET":
+		// Listing objects, versions, uploads and parts are billed as tier 1
+		if resource == "BUCKET" || resource == "BUCKETVERSIONS" || resource == "UPLOADS" || resource == "UPLOAD" {
+			return 1
+		}
+		return 2
+	case "DELETE":
+		return 0
+	default:
+		return 2
+	}
+}
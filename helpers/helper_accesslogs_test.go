@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequestTier(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int
+	}{
+		{input: "REST.PUT.OBJECT", expected: 1},
+		{input: "REST.POST.UPLOADS", expected: 1},
+		{input: "REST.COPY.OBJECT", expected: 1},
+		{input: "REST.COPY.OBJECT_GET", expected: 0},
+		{input: "REST.GET.BUCKET", expected: 1},
+		{input: "REST.GET.OBJECT", expected: 2},
+		{input: "REST.HEAD.OBJECT", expected: 2},
+		{input: "REST.DELETE.OBJECT", expected: 0},
+		{input: "WEBSITE.GET.OBJECT", expected: 2},
+		{input: "S3.TRANSITION_SIA.OBJECT", expected: 0},
+	}
+
+	for _, c := range cases {
+		got := RequestTier(c.input)
+		if got != c.expected {
+			t.Errorf("RequestTier(%s) == %d, want %d", c.input, got, c.expected)
+		}
+	}
+}
+
+func TestParseAccessLogs(t *testing.T) {
+	// Two days of logs, extrapolated to 30 days
+	lines := []string{
+		`79a5 awsexamplebucket1 [06/Feb/2019:00:00:38 +0000] 192.0.2.3 79a5 3E57427F3EXAMPLE REST.GET.VERSIONING - "GET /awsexamplebucket1?versioning HTTP/1.1" 200 - 113 - 7 - "-" "S3Console/0.4" - s9lzHYrFp76ZVxRcpX9+5cjAnEH2ROuNkd2BHfIa6UkFVdtjf5mKR3/eTPFvsiP/XV/VLi31234= SigV4 ECDHE-RSA-AES128-GCM-SHA256 AuthHeader awsexamplebucket1.s3.us-west-1.amazonaws.com TLSV1.2 arn:aws:s3:us-west-1:123456789012:accesspoint/example-AP Yes`,
+		`79a5 awsexamplebucket1 [06/Feb/2019:00:00:38 +0000] 192.0.2.3 79a5 891CE47D2EXAMPLE REST.PUT.OBJECT photos/1.jpg "PUT /awsexamplebucket1/photos/1.jpg HTTP/1.1" 200 - - 1024 7 - "-" "aws-cli" -`,
+		`79a5 awsexamplebucket2 [08/Feb/2019:00:00:38 +0000] 192.0.2.3 79a5 A1206F460EXAMPLE REST.GET.BUCKET - "GET /awsexamplebucket2?list-type=2 HTTP/1.1" 200 - 242 - 11 - "-" "aws-cli" -`,
+		`not a log line`,
+	}
+
+	path := filepath.Join(t.TempDir(), "access.log")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	requests, err := ParseAccessLogs(path)
+	if err != nil {
+		t.Fatalf("ParseAccessLogs returned an error: %s", err)
+	}
+
+	expected := BucketRequests{
+		"awsexamplebucket1": {"": {Tier1: 15, Tier2: 15}},
+		"awsexamplebucket2": {"": {Tier1: 15, Tier2: 0}},
+	}
+	for bucket, storageTypes := range expected {
+		if requests[bucket][""] != storageTypes[""] {
+			t.Errorf("ParseAccessLogs()[%s] == %v, want %v", bucket, requests[bucket][""], storageTypes[""])
+		}
+	}
+}
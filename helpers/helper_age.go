@@ -0,0 +1,25 @@
+package helpers
+
+import "time"
+
+// Age ranges in days
+var AgeHistogramRanges = []HistogramRange{
+	{Key: "0_30d", Label: "<30 days", UpTo: 30},
+	{Key: "30d_90d", Label: "30-90 days", UpTo: 90},
+	{Key: "90d_180d", Label: "90-180 days", UpTo: 180},
+	{Key: "180d_1y", Label: "180-365 days", UpTo: 365},
+	{Key: "1y_3y", Label: "1-3 years", UpTo: 3 * 365},
+	{Key: "3y_plus", Label: ">3 years", UpTo: 0},
+}
+
+// Objects not modified for more than DefaultStaleDays are considered stale unless --stale-days is set
+const DefaultStaleDays = 90
+
+// AgeInDays returns the number of full days since the last modification
+func AgeInDays(lastModified time.Time) int {
+	return int(time.Since(lastModified) / (24 * time.Hour))
+}
+
+func IsStale(lastModified time.Time, staleDays int) bool {
+	return AgeInDays(lastModified) > staleDays
+}
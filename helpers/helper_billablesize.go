@@ -0,0 +1,73 @@
+package helpers
+
+// Objects under 128 KB are billed as 128 KB in STANDARD_IA, ONEZONE_IA and GLACIER_IR, and are
+// not monitored by Intelligent-Tiering (they always stay in the Frequent Access tier)
+const SmallObjectSize = 128 * 1024
+
+// GLACIER and DEEP_ARCHIVE store metadata for each object, part of it billed at the STANDARD rate
+const (
+	archiveMetadataSize         = 32 * 1024
+	archiveStandardMetadataSize = 8 * 1024
+)
+
+// StorageCost details the monthly storage cost of the objects of a storage type
+type StorageCost struct {
+	ActualBytes int
+	// Bytes billed at the storage type rate, including the minimum billable size and metadata
+	BillableBytes int
+	// Metadata bytes of archived objects billed at the STANDARD rate
+	StandardMetadataBytes int
+
+	Cost float64
+	// Part of the cost caused by the minimum billable size and the metadata
+	OverheadCost float64
+	// Minimum storage duration left on the objects younger than it, billed once if they are deleted
+	// or transitioned now. It is not part of the monthly Cost.
+	MinimumDurationCost float64
+}
+
+// CalculateStorageCost prices the objects of a storage type like CalculateSharedObjectsCost, adding
+// the minimum billable size of the objects under 128 KB and the metadata of archived objects
+func (p Pricing) CalculateStorageCost(storageType, region string, sizeInBytes, objectNumber, smallObjectsNumber, smallObjectsSize, regionSizeInBytes int) (StorageCost, error) {
+	result := StorageCost{
+		ActualBytes:   sizeInBytes,
+		BillableBytes: sizeInBytes,
+	}
+
+	switch storageType {
+	case "STANDARD_IA", "ONEZONE_IA", "GLACIER_IR":
+		result.BillableBytes += smallObjectsNumber*SmallObjectSize - smallObjectsSize
+	case "GLACIER", "DEEP_ARCHIVE":
+		result.BillableBytes += objectNumber * archiveMetadataSize
+		result.StandardMetadataBytes = objectNumber * archiveStandardMetadataSize
+	}
+
+	actualCost, err := p.CalculateSharedObjectsCost(storageType, region, sizeInBytes, objectNumber, regionSizeInBytes)
+	if err != nil {
+		return result, err
+	}
+
+	if result.BillableBytes == result.ActualBytes && result.StandardMetadataBytes == 0 {
+		result.Cost = actualCost
+		return result, nil
+	}
+
+	cost, err := p.CalculateSharedObjectsCost(storageType, region, result.BillableBytes, objectNumber, regionSizeInBytes-sizeInBytes+result.BillableBytes)
+	if err != nil {
+		return result, err
+	}
+
+	// The metadata is priced as if it was the only STANDARD usage of the region, which can only
+	// overestimate it slightly
+	if result.StandardMetadataBytes > 0 {
+		standardCost, err := p.CalculateObjectsCostByStorageType("STANDARD", region, result.StandardMetadataBytes, objectNumber)
+		if err != nil {
+			return result, err
+		}
+		cost += standardCost
+	}
+
+	result.Cost = round2(cost)
+	result.OverheadCost = round2(result.Cost - actualCost)
+	return result, nil
+}
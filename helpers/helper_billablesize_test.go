@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestCalculateStorageCost(t *testing.T) {
+	cases := []struct {
+		storageType        string
+		sizeInBytes        int
+		objectNumber       int
+		smallObjectsNumber int
+		smallObjectsSize   int
+		expected           StorageCost
+	}{
+		{ // No minimum size nor metadata
+			storageType:  "STANDARD",
+			sizeInBytes:  10 * testGB,
+			objectNumber: 10,
+			expected:     StorageCost{ActualBytes: 10 * testGB, BillableBytes: 10 * testGB, Cost: 0.23},
+		},
+		{ // 10,000,000 objects of 1 KB billed as 128 KB
+			storageType:        "STANDARD_IA",
+			sizeInBytes:        10000000 * 1024,
+			objectNumber:       10000000,
+			smallObjectsNumber: 10000000,
+			smallObjectsSize:   10000000 * 1024,
+			expected:           StorageCost{ActualBytes: 10000000 * 1024, BillableBytes: 1310720000000, Cost: 15.26, OverheadCost: 15.14},
+		},
+		{ // Large objects are billed on their size
+			storageType:  "GLACIER_IR",
+			sizeInBytes:  100 * testGB,
+			objectNumber: 100,
+			expected:     StorageCost{ActualBytes: 100 * testGB, BillableBytes: 100 * testGB, Cost: 0.4},
+		},
+		{ // 1,000,000 objects of 1 MB with 32 KB of metadata each, and 8 KB at the STANDARD rate
+			storageType:  "GLACIER",
+			sizeInBytes:  1000000 * 1024 * 1024,
+			objectNumber: 1000000,
+			expected:     StorageCost{ActualBytes: 1000000 * 1024 * 1024, BillableBytes: 1081344000000, StandardMetadataBytes: 8192000000, Cost: 3.81, OverheadCost: 0.29},
+		},
+	}
+
+	for _, c := range cases {
+		got, err := Pricing{}.CalculateStorageCost(c.storageType, "us-east-1", c.sizeInBytes, c.objectNumber, c.smallObjectsNumber, c.smallObjectsSize, c.sizeInBytes)
+		if err != nil {
+			t.Errorf("CalculateStorageCost(%s, %d) returned an error: %s", c.storageType, c.sizeInBytes, err)
+		}
+		if got != c.expected {
+			t.Errorf("CalculateStorageCost(%s, %d) == %+v, want %+v", c.storageType, c.sizeInBytes, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"testing"
+)
+
+const (
+	testGB = 1024 * 1024 * 1024
+	testTB = 1024 * testGB
+)
+
+func TestCalculateObjectsCostByStorageType(t *testing.T) {
+	cases := []struct {
+		storageType string
+		region      string
+		sizeInBytes int
+		expected    float64
+	}{
+		{ // Empty
+			storageType: "STANDARD",
+			region:      "us-east-1",
+			sizeInBytes: 0,
+			expected:    0,
+		},
+		{ // Fractional gigabytes are billed
+			storageType: "STANDARD",
+			region:      "us-east-1",
+			sizeInBytes: testGB / 2,
+			expected:    0.01,
+		},
+		{
+			storageType: "STANDARD",
+			region:      "us-east-1",
+			sizeInBytes: 10 * testGB,
+			expected:    0.23,
+		},
+		{ // Last byte of the first tier
+			storageType: "STANDARD",
+			region:      "us-east-1",
+			sizeInBytes: 50 * testTB,
+			expected:    1177.6,
+		},
+		{ // 1 TB in the second tier
+			storageType: "STANDARD",
+			region:      "us-east-1",
+			sizeInBytes: 51 * testTB,
+			expected:    1200.13,
+		},
+		{ // Last byte of the second tier
+			storageType: "STANDARD",
+			region:      "us-east-1",
+			sizeInBytes: 500 * testTB,
+			expected:    11315.2,
+		},
+		{ // 1 TB in the last tier
+			storageType: "STANDARD",
+			region:      "us-east-1",
+			sizeInBytes: 501 * testTB,
+			expected:    11336.7,
+		},
+		{ // Region group rates
+			storageType: "STANDARD",
+			region:      "sa-east-1",
+			sizeInBytes: 100 * testGB,
+			expected:    4.05,
+		},
+		{ // Last byte of the first tier
+			storageType: "REDUCED_REDUNDANCY",
+			region:      "us-east-1",
+			sizeInBytes: testTB,
+			expected:    24.58,
+		},
+		{ // 1 TB in the second tier
+			storageType: "REDUCED_REDUNDANCY",
+			region:      "us-east-1",
+			sizeInBytes: 2 * testTB,
+			expected:    48.74,
+		},
+		{ // Single tier
+			storageType: "GLACIER",
+			region:      "us-east-1",
+			sizeInBytes: 100 * testGB,
+			expected:    0.36,
+		},
+		{ // Single tier
+			storageType: "DEEP_ARCHIVE",
+			region:      "eu-central-1",
+			sizeInBytes: testTB,
+			expected:    1.84,
+		},
+		{ // Not billed
+			storageType: "OUTPOSTS",
+			region:      "us-east-1",
+			sizeInBytes: testTB,
+			expected:    0,
+		},
+	}
+
+	for _, c := range cases {
+		got, err := Pricing{}.CalculateObjectsCostByStorageType(c.storageType, c.region, c.sizeInBytes, 0)
+		if err != nil {
+			t.Errorf("CalculateObjectsCostByStorageType(%s, %s, %d) returned an error: %s", c.storageType, c.region, c.sizeInBytes, err)
+		}
+		if got != c.expected {
+			t.Errorf("CalculateObjectsCostByStorageType(%s, %s, %d) == %v, want %v", c.storageType, c.region, c.sizeInBytes, got, c.expected)
+		}
+	}
+}
+
+func TestCalculateObjectsCostByStorageTypeErrors(t *testing.T) {
+	cases := []struct {
+		storageType string
+		region      string
+	}{
+		{storageType: "STANDARD", region: "moon-central-1"},
+		{storageType: "EXPRESS_ONEZONE", region: "ca-central-1"},
+		{storageType: "PAPER", region: "us-east-1"},
+	}
+
+	for _, c := range cases {
+		_, err := Pricing{}.CalculateObjectsCostByStorageType(c.storageType, c.region, testGB, 1)
+		if err == nil {
+			t.Errorf("CalculateObjectsCostByStorageType(%s, %s) should return an error", c.storageType, c.region)
+		}
+	}
+}
+
+func TestCalculateSharedObjectsCost(t *testing.T) {
+	cases := []struct {
+		sizeInBytes       int
+		regionSizeInBytes int
+		expected          float64
+	}{
+		{ // Alone in the region
+			sizeInBytes:       testTB,
+			regionSizeInBytes: testTB,
+			expected:          23.55,
+		},
+		{ // 1 TB out of 100 TB: (51200 * 0.023 + 51200 * 0.022) / 100
+			sizeInBytes:       testTB,
+			regionSizeInBytes: 100 * testTB,
+			expected:          23.04,
+		},
+		{ // The region usage cannot be smaller than the objects
+			sizeInBytes:       testTB,
+			regionSizeInBytes: 0,
+			expected:          23.55,
+		},
+	}
+
+	for _, c := range cases {
+		got, err := Pricing{}.CalculateSharedObjectsCost("STANDARD", "us-east-1", c.sizeInBytes, 0, c.regionSizeInBytes)
+		if err != nil {
+			t.Errorf("CalculateSharedObjectsCost(%d, %d) returned an error: %s", c.sizeInBytes, c.regionSizeInBytes, err)
+		}
+		if got != c.expected {
+			t.Errorf("CalculateSharedObjectsCost(%d, %d) == %v, want %v", c.sizeInBytes, c.regionSizeInBytes, got, c.expected)
+		}
+	}
+}
@@ -48,3 +48,17 @@ func GetUnit(unit string) (int, error) {
 		return B, fmt.Errorf("invalid unit %s", unit)
 	}
 }
+
+// FormatCount shortens large numbers of objects, e.g. 2.1M for 2,100,000
+func FormatCount(count int) string {
+	switch {
+	case count >= 1000000000:
+		return fmt.Sprintf("%.1fB", float64(count)/1000000000)
+	case count >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(count)/1000000)
+	case count >= 1000:
+		return fmt.Sprintf("%.1fK", float64(count)/1000)
+	default:
+		return fmt.Sprintf("%d", count)
+	}
+}
@@ -163,3 +163,21 @@ func TestGetUnit(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatCount(t *testing.T) {
+	cases := []struct {
+		input    int
+		expected string
+	}{
+		{input: 999, expected: "999"},
+		{input: 1500, expected: "1.5K"},
+		{input: 2100000, expected: "2.1M"},
+		{input: 3000000000, expected: "3.0B"},
+	}
+
+	for _, c := range cases {
+		if got := FormatCount(c.input); got != c.expected {
+			t.Errorf("FormatCount(%d) == %s, want %s", c.input, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package helpers
+
+import "strings"
+
+// HistogramRange is a range of a histogram, UpTo is exclusive and 0 for the last unbounded range
+type HistogramRange struct {
+	Key   string
+	Label string
+	UpTo  int
+}
+
+var SizeHistogramRanges = []HistogramRange{
+	{Key: "0_1kb", Label: "0-1 KB", UpTo: 1024},
+	{Key: "1kb_128kb", Label: "1-128 KB", UpTo: 128 * 1024},
+	{Key: "128kb_1mb", Label: "128 KB-1 MB", UpTo: 1024 * 1024},
+	{Key: "1mb_16mb", Label: "1-16 MB", UpTo: 16 * 1024 * 1024},
+	{Key: "16mb_128mb", Label: "16-128 MB", UpTo: 128 * 1024 * 1024},
+	{Key: "128mb_1gb", Label: "128 MB-1 GB", UpTo: 1024 * 1024 * 1024},
+	{Key: "1gb_5gb", Label: "1-5 GB", UpTo: 5 * 1024 * 1024 * 1024},
+	{Key: "5gb_plus", Label: ">5 GB", UpTo: 0},
+}
+
+// HistogramIndex returns the index of the range the value falls in
+func HistogramIndex(ranges []HistogramRange, value int) int {
+	for i, r := range ranges {
+		if r.UpTo == 0 || value < r.UpTo {
+			return i
+		}
+	}
+	return len(ranges) - 1
+}
+
+// FormatHistogramBar draws a bar proportional to value, width being the length of the bar of max
+func FormatHistogramBar(value, max, width int) string {
+	if max == 0 {
+		return strings.Repeat(" ", width)
+	}
+
+	length := value * width / max
+	if value > 0 && length == 0 {
+		length = 1
+	}
+	return strings.Repeat("#", length) + strings.Repeat(" ", width-length)
+}
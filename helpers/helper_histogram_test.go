@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestHistogramIndex(t *testing.T) {
+	cases := []struct {
+		input    int
+		expected string
+	}{
+		{input: 0, expected: "0-1 KB"},
+		{input: 1023, expected: "0-1 KB"},
+		{input: 1024, expected: "1-128 KB"},
+		{input: 128 * 1024, expected: "128 KB-1 MB"},
+		{input: 200 * 1024 * 1024, expected: "128 MB-1 GB"},
+		{input: 5 * 1024 * 1024 * 1024, expected: ">5 GB"},
+	}
+
+	for _, c := range cases {
+		got := SizeHistogramRanges[HistogramIndex(SizeHistogramRanges, c.input)].Label
+		if got != c.expected {
+			t.Errorf("HistogramIndex(%d) == %s, want %s", c.input, got, c.expected)
+		}
+	}
+}
+
+func TestFormatHistogramBar(t *testing.T) {
+	cases := []struct {
+		value    int
+		max      int
+		expected string
+	}{
+		{value: 10, max: 10, expected: "##########"},
+		{value: 5, max: 10, expected: "#####     "},
+		{value: 1, max: 1000, expected: "#         "},
+		{value: 0, max: 10, expected: "          "},
+		{value: 0, max: 0, expected: "          "},
+	}
+
+	for _, c := range cases {
+		got := FormatHistogramBar(c.value, c.max, 10)
+		if got != c.expected {
+			t.Errorf("FormatHistogramBar(%d, %d) == %q, want %q", c.value, c.max, got, c.expected)
+		}
+	}
+}
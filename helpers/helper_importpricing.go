@@ -0,0 +1,283 @@
+package helpers
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Subset of the AWS Price List bulk offer file (https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonS3/current/index.json)
+type priceListOffer struct {
+	OfferCode string                      `json:"offerCode"`
+	Version   string                      `json:"version"`
+	Products  map[string]priceListProduct `json:"products"`
+	Terms     struct {
+		OnDemand map[string]map[string]priceListTerm `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+type priceListProduct struct {
+	Sku        string            `json:"sku"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+type priceListTerm struct {
+	PriceDimensions map[string]priceListDimension `json:"priceDimensions"`
+}
+
+type priceListDimension struct {
+	Unit         string            `json:"unit"`
+	BeginRange   string            `json:"beginRange"`
+	EndRange     string            `json:"endRange"`
+	PricePerUnit map[string]string `json:"pricePerUnit"`
+}
+
+// Usage types of the offer file, without their region prefix (e.g. "EUW2-"), mapped to storage classes
+var storageUsageTypes = map[string]string{
+	"TimedStorage-ByteHrs":         "STANDARD",
+	"TimedStorage-RRS-ByteHrs":     "REDUCED_REDUNDANCY",
+	"TimedStorage-SIA-ByteHrs":     "STANDARD_IA",
+	"TimedStorage-ZIA-ByteHrs":     "ONEZONE_IA",
+	"TimedStorage-GlacierByteHrs":  "GLACIER",
+	"TimedStorage-GIR-ByteHrs":     "GLACIER_IR",
+	"TimedStorage-GDA-ByteHrs":     "DEEP_ARCHIVE",
+	"TimedStorage-XZ-ByteHrs":      "EXPRESS_ONEZONE",
+	"TimedStorage-INT-FA-ByteHrs":  "INTELLIGENT_TIERING_FREQUENT_ACCESS",
+	"TimedStorage-INT-IA-ByteHrs":  "INTELLIGENT_TIERING_INFREQUENT_ACCESS",
+	"TimedStorage-INT-AIA-ByteHrs": "INTELLIGENT_TIERING_ARCHIVE_INSTANT_ACCESS",
+	"TimedStorage-INT-AA-ByteHrs":  "INTELLIGENT_TIERING_ARCHIVE_ACCESS",
+	"TimedStorage-INT-DAA-ByteHrs": "INTELLIGENT_TIERING_DEEP_ARCHIVE_ACCESS",
+}
+
+// Intelligent-Tiering monitoring and automation fee, priced per object
+const monitoringUsageType = "Monitoring-Automation-INT"
+
+var tier1RequestUsageTypes = map[string]string{
+	"Requests-Tier1":         "STANDARD",
+	"Requests-SIA-Tier1":     "STANDARD_IA",
+	"Requests-ZIA-Tier1":     "ONEZONE_IA",
+	"Requests-GLACIER-Tier1": "GLACIER",
+	"Requests-GIR-Tier1":     "GLACIER_IR",
+	"Requests-GDA-Tier1":     "DEEP_ARCHIVE",
+	"Requests-XZ-Tier1":      "EXPRESS_ONEZONE",
+}
+
+var tier2RequestUsageTypes = map[string]string{
+	"Requests-Tier2":         "STANDARD",
+	"Requests-SIA-Tier2":     "STANDARD_IA",
+	"Requests-ZIA-Tier2":     "ONEZONE_IA",
+	"Requests-GLACIER-Tier2": "GLACIER",
+	"Requests-GIR-Tier2":     "GLACIER_IR",
+	"Requests-GDA-Tier2":     "DEEP_ARCHIVE",
+	"Requests-XZ-Tier2":      "EXPRESS_ONEZONE",
+}
+
+// GLACIER and DEEP_ARCHIVE restores are priced per retrieval option and are not imported
+var retrievalUsageTypes = map[string]string{
+	"Retrieval-SIA": "STANDARD_IA",
+	"Retrieval-ZIA": "ONEZONE_IA",
+	"Retrieval-GIR": "GLACIER_IR",
+}
+
+// Storage classes billed with the STANDARD request rates
+var standardRequestsStorageClasses = []string{"REDUCED_REDUNDANCY", "INTELLIGENT_TIERING_FREQUENT_ACCESS"}
+
+// ImportPriceListOffer builds a pricing catalog with one region group per region from an
+// AmazonS3 Price List offer file. Storage classes without storage rates in a region are
+// marked unavailable, as is Intelligent-Tiering in the regions without its monitoring fee. Regions
+// without any storage rate are skipped.
+func ImportPriceListOffer(data []byte) (*PricingCatalog, error) {
+	offer := priceListOffer{}
+	if err := json.Unmarshal(data, &offer); err != nil {
+		return nil, fmt.Errorf("invalid offer file: %w", err)
+	}
+
+	if offer.OfferCode != "AmazonS3" {
+		return nil, fmt.Errorf("invalid offer file: expected the AmazonS3 offer, got %v", offer.OfferCode)
+	}
+
+	regions := map[string]map[string]StorageClassPricing{}
+	monitoringFees := map[string]float64{}
+	for sku, product := range offer.Products {
+		region := product.Attributes["regionCode"]
+		usageType := product.Attributes["usagetype"]
+		if region == "" || usageType == "" {
+			continue
+		}
+
+		dimensions := offer.dimensions(sku)
+		if len(dimensions) == 0 {
+			continue
+		}
+
+		if regions[region] == nil {
+			regions[region] = map[string]StorageClassPricing{}
+		}
+		storageClasses := regions[region]
+
+		if storageType, ok := matchUsageType(usageType, storageUsageTypes); ok {
+			tiers, err := priceTiers(dimensions)
+			if err != nil {
+				return nil, fmt.Errorf("sku %v: %w", sku, err)
+			}
+
+			pricing := storageClasses[storageType]
+			pricing.Tiers = tiers
+			storageClasses[storageType] = pricing
+		}
+
+		if storageType, ok := matchUsageType(usageType, tier1RequestUsageTypes); ok {
+			pricing := storageClasses[storageType]
+			pricing.Tier1RequestsPer1000 = dimensionPrice(dimensions) * 1000
+			storageClasses[storageType] = pricing
+		}
+
+		if storageType, ok := matchUsageType(usageType, tier2RequestUsageTypes); ok {
+			pricing := storageClasses[storageType]
+			pricing.Tier2RequestsPer1000 = dimensionPrice(dimensions) * 1000
+			storageClasses[storageType] = pricing
+		}
+
+		if storageType, ok := matchUsageType(usageType, retrievalUsageTypes); ok {
+			pricing := storageClasses[storageType]
+			pricing.RetrievalPerGB = dimensionPrice(dimensions)
+			storageClasses[storageType] = pricing
+		}
+
+		if _, ok := matchUsageType(usageType, map[string]string{monitoringUsageType: ""}); ok {
+			monitoringFees[region] = dimensionPrice(dimensions) * 1000
+		}
+	}
+
+	catalog := &PricingCatalog{
+		Version:      offer.Version,
+		Currency:     "USD",
+		RegionGroups: []RegionGroupPricing{},
+	}
+
+	regionNames := []string{}
+	for region := range regions {
+		regionNames = append(regionNames, region)
+	}
+	slices.Sort(regionNames)
+
+	for _, region := range regionNames {
+		storageClasses := regions[region]
+
+		// Intelligent-Tiering cannot be priced without its monitoring fee
+		if monitoringFees[region] <= 0 {
+			for storageType := range storageClasses {
+				if strings.HasPrefix(storageType, intelligentTieringPrefix) {
+					delete(storageClasses, storageType)
+				}
+			}
+		}
+
+		available := 0
+		for _, storageType := range catalogStorageClasses {
+			pricing := storageClasses[storageType]
+			if len(pricing.Tiers) == 0 {
+				storageClasses[storageType] = StorageClassPricing{Unavailable: true}
+				continue
+			}
+
+			if slices.Contains(standardRequestsStorageClasses, storageType) {
+				pricing.Tier1RequestsPer1000 = storageClasses["STANDARD"].Tier1RequestsPer1000
+				pricing.Tier2RequestsPer1000 = storageClasses["STANDARD"].Tier2RequestsPer1000
+				storageClasses[storageType] = pricing
+			}
+			available++
+		}
+
+		if available == 0 {
+			continue
+		}
+
+		// Drop the rates of storage classes the catalog does not describe
+		for storageType := range storageClasses {
+			if !slices.Contains(catalogStorageClasses, storageType) {
+				delete(storageClasses, storageType)
+			}
+		}
+
+		catalog.RegionGroups = append(catalog.RegionGroups, RegionGroupPricing{
+			Name:    region,
+			Regions: []string{region},
+			IntelligentTieringMonitoringPer1000Objects: monitoringFees[region],
+			StorageClasses: storageClasses,
+		})
+	}
+
+	if err := catalog.validate(); err != nil {
+		return nil, err
+	}
+
+	return catalog, nil
+}
+
+func (o priceListOffer) dimensions(sku string) []priceListDimension {
+	dimensions := []priceListDimension{}
+	for _, term := range o.Terms.OnDemand[sku] {
+		for _, dimension := range term.PriceDimensions {
+			dimensions = append(dimensions, dimension)
+		}
+	}
+	return dimensions
+}
+
+func matchUsageType(usageType string, usageTypes map[string]string) (string, bool) {
+	// us-east-1 usage types have no region prefix
+	if storageType, ok := usageTypes[usageType]; ok {
+		return storageType, true
+	}
+
+	_, withoutPrefix, found := strings.Cut(usageType, "-")
+	if !found {
+		return "", false
+	}
+
+	storageType, ok := usageTypes[withoutPrefix]
+	return storageType, ok
+}
+
+// sortDimensions orders the dimensions of a SKU by range, they come from a map in the offer file
+func sortDimensions(dimensions []priceListDimension) {
+	slices.SortFunc(dimensions, func(a, b priceListDimension) int {
+		return cmp.Compare(parseRange(a.BeginRange), parseRange(b.BeginRange))
+	})
+}
+
+func priceTiers(dimensions []priceListDimension) ([]PriceTier, error) {
+	sortDimensions(dimensions)
+
+	tiers := []PriceTier{}
+	for _, dimension := range dimensions {
+		price, err := strconv.ParseFloat(dimension.PricePerUnit["USD"], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price %v", dimension.PricePerUnit["USD"])
+		}
+
+		tier := PriceTier{PricePerGB: price}
+		if dimension.EndRange != "Inf" && dimension.EndRange != "" {
+			tier.UpToGB = parseRange(dimension.EndRange)
+		}
+		tiers = append(tiers, tier)
+	}
+
+	return tiers, nil
+}
+
+// dimensionPrice returns the price of the first range of a SKU billed per unit
+func dimensionPrice(dimensions []priceListDimension) float64 {
+	sortDimensions(dimensions)
+	price, _ := strconv.ParseFloat(dimensions[0].PricePerUnit["USD"], 64)
+	return price
+}
+
+func parseRange(value string) float64 {
+	result, _ := strconv.ParseFloat(value, 64)
+	return result
+}
@@ -0,0 +1,89 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestImportPriceListOffer(t *testing.T) {
+	product := func(sku, region, usageType string) string {
+		return fmt.Sprintf(`"%s": {"sku": "%s", "attributes": {"regionCode": "%s", "usagetype": "%s"}}`, sku, sku, region, usageType)
+	}
+	term := func(sku string, dimensions ...string) string {
+		return fmt.Sprintf(`"%s": {"%s.TERM": {"priceDimensions": {%s}}}`, sku, sku, strings.Join(dimensions, ","))
+	}
+	dimension := func(id, begin, end, price string) string {
+		return fmt.Sprintf(`"%s": {"unit": "GB-Mo", "beginRange": "%s", "endRange": "%s", "pricePerUnit": {"USD": "%s"}}`, id, begin, end, price)
+	}
+
+	offer := `{
+		"offerCode": "AmazonS3",
+		"version": "20250201000000",
+		"products": {` + strings.Join([]string{
+		product("STD", "us-east-1", "TimedStorage-ByteHrs"),
+		product("SIA", "eu-west-2", "EUW2-TimedStorage-SIA-ByteHrs"),
+		product("T1", "us-east-1", "Requests-Tier1"),
+		product("T2", "us-east-1", "Requests-Tier2"),
+		product("RET", "eu-west-2", "EUW2-Retrieval-SIA"),
+		product("INT", "eu-west-2", "EUW2-TimedStorage-INT-FA-ByteHrs"),
+		product("OTHER", "us-east-1", "DataTransfer-Out-Bytes"),
+	}, ",") + `},
+		"terms": {"OnDemand": {` + strings.Join([]string{
+		// Dimensions are not ordered in the offer file
+		term("STD", dimension("b", "51200", "512000", "0.022"), dimension("a", "0", "51200", "0.023"), dimension("c", "512000", "Inf", "0.021")),
+		term("SIA", dimension("a", "0", "Inf", "0.0131")),
+		// Only the first range of a SKU billed per request is imported
+		term("T1", dimension("b", "1000000000", "Inf", "0.000004"), dimension("a", "0", "1000000000", "0.000005")),
+		term("T2", dimension("a", "0", "Inf", "0.0000004")),
+		term("RET", dimension("a", "0", "Inf", "0.01")),
+		term("INT", dimension("a", "0", "Inf", "0.024")),
+		term("OTHER", dimension("a", "0", "Inf", "0.09")),
+	}, ",") + `}}
+	}`
+
+	catalog, err := ImportPriceListOffer([]byte(offer))
+	if err != nil {
+		t.Fatalf("ImportPriceListOffer returned an error: %s", err)
+	}
+
+	if len(catalog.RegionGroups) != 2 {
+		t.Fatalf("ImportPriceListOffer returned %d region groups, want 2", len(catalog.RegionGroups))
+	}
+
+	standard, err := catalog.StorageClass("us-east-1", "STANDARD")
+	if err != nil {
+		t.Fatalf("StorageClass(us-east-1, STANDARD) returned an error: %s", err)
+	}
+	expectedTiers := []PriceTier{{UpToGB: 51200, PricePerGB: 0.023}, {UpToGB: 512000, PricePerGB: 0.022}, {PricePerGB: 0.021}}
+	if fmt.Sprint(standard.Tiers) != fmt.Sprint(expectedTiers) {
+		t.Errorf("STANDARD tiers == %v, want %v", standard.Tiers, expectedTiers)
+	}
+	if round2(standard.Tier1RequestsPer1000*1000) != 5 || round2(standard.Tier2RequestsPer1000*1000) != 0.4 {
+		t.Errorf("STANDARD requests == %v/%v per 1000, want 0.005/0.0004", standard.Tier1RequestsPer1000, standard.Tier2RequestsPer1000)
+	}
+
+	infrequentAccess, err := catalog.StorageClass("eu-west-2", "STANDARD_IA")
+	if err != nil {
+		t.Fatalf("StorageClass(eu-west-2, STANDARD_IA) returned an error: %s", err)
+	}
+	if infrequentAccess.RetrievalPerGB != 0.01 {
+		t.Errorf("STANDARD_IA retrieval == %v, want 0.01", infrequentAccess.RetrievalPerGB)
+	}
+
+	if _, err := catalog.StorageClass("eu-west-2", "STANDARD"); err == nil {
+		t.Errorf("StorageClass(eu-west-2, STANDARD) should be unavailable")
+	}
+
+	// The offer has Frequent Access rates in eu-west-2 but no monitoring fee
+	if _, err := catalog.StorageClass("eu-west-2", "INTELLIGENT_TIERING_FREQUENT_ACCESS"); err == nil {
+		t.Errorf("StorageClass(eu-west-2, INTELLIGENT_TIERING_FREQUENT_ACCESS) should be unavailable")
+	}
+}
+
+func TestImportPriceListOfferInvalid(t *testing.T) {
+	_, err := ImportPriceListOffer([]byte(`{"offerCode": "AmazonEC2"}`))
+	if err == nil {
+		t.Errorf("ImportPriceListOffer(AmazonEC2) should return an error")
+	}
+}
@@ -0,0 +1,137 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Intelligent-Tiering access tiers, in the order objects move through them
+const (
+	FrequentAccess       = "FREQUENT_ACCESS"
+	InfrequentAccess     = "INFREQUENT_ACCESS"
+	ArchiveInstantAccess = "ARCHIVE_INSTANT_ACCESS"
+	ArchiveAccess        = "ARCHIVE_ACCESS"
+	DeepArchiveAccess    = "DEEP_ARCHIVE_ACCESS"
+)
+
+// Catalog storage classes of the access tiers are named INTELLIGENT_TIERING_<tier>
+const intelligentTieringPrefix = "INTELLIGENT_TIERING_"
+
+var IntelligentTieringAccessTiers = []string{FrequentAccess, InfrequentAccess, ArchiveInstantAccess, ArchiveAccess, DeepArchiveAccess}
+
+// Names accepted by ParseIntelligentTieringSplit
+var intelligentTieringFlagNames = map[string]string{
+	"frequent":        FrequentAccess,
+	"infrequent":      InfrequentAccess,
+	"archive-instant": ArchiveInstantAccess,
+	"archive":         ArchiveAccess,
+	"deep-archive":    DeepArchiveAccess,
+}
+
+// IntelligentTieringSplit is the share of the bytes stored in each access tier, the shares add up to 1
+type IntelligentTieringSplit map[string]float64
+
+// ParseIntelligentTieringSplit reads percentages such as 'frequent:40;infrequent:30;archive-instant:30'
+func ParseIntelligentTieringSplit(value string) (IntelligentTieringSplit, error) {
+	split := IntelligentTieringSplit{}
+	total := 0.0
+
+	for _, option := range strings.Split(value, ";") {
+		keyValue := strings.Split(option, ":")
+		if len(keyValue) != 2 {
+			return nil, fmt.Errorf("invalid intelligent tiering option. please use a tier and a percentage separated by a colon")
+		}
+
+		tier, ok := intelligentTieringFlagNames[keyValue[0]]
+		if !ok {
+			return nil, fmt.Errorf("invalid intelligent tiering tier. please use 'frequent', 'infrequent', 'archive-instant', 'archive' or 'deep-archive'")
+		}
+
+		percentage, err := strconv.ParseFloat(keyValue[1], 64)
+		if err != nil || percentage < 0 {
+			return nil, fmt.Errorf("invalid intelligent tiering percentage %v", keyValue[1])
+		}
+
+		split[tier] = percentage / 100
+		total += percentage
+	}
+
+	if math.Abs(total-100) > 0.01 {
+		return nil, fmt.Errorf("intelligent tiering percentages should add up to 100 (received: %v)", total)
+	}
+
+	return split, nil
+}
+
+// InferIntelligentTieringAccessTier guesses the tier of a monitored object using its last modified
+// date as the last access. Archive Access and Deep Archive Access are opt-in and are never inferred.
+func InferIntelligentTieringAccessTier(lastModified time.Time) string {
+	age := time.Since(lastModified)
+
+	switch {
+	case age < 30*24*time.Hour:
+		return FrequentAccess
+	case age < 90*24*time.Hour:
+		return InfrequentAccess
+	default:
+		return ArchiveInstantAccess
+	}
+}
+
+// DefaultIntelligentTieringSplit returns the configured access tier split. Without one, everything
+// is assumed to be frequently accessed which is the most expensive case.
+func (p Pricing) DefaultIntelligentTieringSplit() IntelligentTieringSplit {
+	if p.IntelligentTieringSplit == nil {
+		return IntelligentTieringSplit{FrequentAccess: 1}
+	}
+	return p.IntelligentTieringSplit
+}
+
+// CalculateIntelligentTieringCost prices the bytes of each access tier plus the monitoring fee of
+// the objects of 128 KB and more. The split only applies to the monitored objects, the
+// smallObjectsSize bytes of the objects under 128 KB are not auto-tiered and stay in Frequent
+// Access. The account usage of each tier is approximated by applying the tier shares of the
+// objects to regionSizeInBytes.
+func (p Pricing) CalculateIntelligentTieringCost(region string, sizeInBytes, monitoredObjects, smallObjectsSize, regionSizeInBytes int, split IntelligentTieringSplit) (float64, error) {
+	monitoringFee, err := p.catalog().IntelligentTieringMonitoringFee(region)
+	if err != nil {
+		return 0.0, err
+	}
+	totalCost := monitoringFee * float64(monitoredObjects) / 1000
+	if sizeInBytes == 0 {
+		return round2(totalCost), nil
+	}
+
+	monitoredSize := float64(sizeInBytes - smallObjectsSize)
+	for _, tier := range IntelligentTieringAccessTiers {
+		tierSize := monitoredSize * split[tier]
+		if tier == FrequentAccess {
+			tierSize += float64(smallObjectsSize)
+		}
+		if tierSize == 0 {
+			continue
+		}
+
+		share := tierSize / float64(sizeInBytes)
+		cost, err := p.calculateSharedCost(intelligentTieringPrefix+tier, region, tierSize, float64(regionSizeInBytes)*share)
+		if err != nil {
+			return 0.0, err
+		}
+		totalCost += cost
+	}
+
+	return round2(totalCost), nil
+}
+
+func (s IntelligentTieringSplit) String() string {
+	parts := []string{}
+	for _, tier := range IntelligentTieringAccessTiers {
+		if share, ok := s[tier]; ok && share > 0 {
+			parts = append(parts, fmt.Sprintf("%v %.0f%%", strings.ToLower(strings.ReplaceAll(tier, "_", " ")), share*100))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,93 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestParseIntelligentTieringSplit(t *testing.T) {
+	cases := []struct {
+		input     string
+		expected  IntelligentTieringSplit
+		expectErr bool
+	}{
+		{
+			input:    "frequent:40;infrequent:30;archive-instant:30",
+			expected: IntelligentTieringSplit{FrequentAccess: 0.4, InfrequentAccess: 0.3, ArchiveInstantAccess: 0.3},
+		},
+		{
+			input:    "deep-archive:100",
+			expected: IntelligentTieringSplit{DeepArchiveAccess: 1},
+		},
+		{ // Does not add up to 100
+			input:     "frequent:40;infrequent:30",
+			expectErr: true,
+		},
+		{ // Unknown tier
+			input:     "glacier:100",
+			expectErr: true,
+		},
+		{ // Missing percentage
+			input:     "frequent",
+			expectErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		got, err := ParseIntelligentTieringSplit(c.input)
+		if (err != nil) != c.expectErr {
+			t.Errorf("ParseIntelligentTieringSplit(%s) returned error %v, expected an error: %v", c.input, err, c.expectErr)
+			continue
+		}
+		for tier, share := range c.expected {
+			if got[tier] != share {
+				t.Errorf("ParseIntelligentTieringSplit(%s)[%s] == %v, want %v", c.input, tier, got[tier], share)
+			}
+		}
+	}
+}
+
+func TestCalculateIntelligentTieringCost(t *testing.T) {
+	cases := []struct {
+		sizeInBytes      int
+		monitoredObjects int
+		smallObjectsSize int
+		split            IntelligentTieringSplit
+		expected         float64
+	}{
+		{ // Only the monitoring fee: 1,000,000 objects at $0.0025 per 1,000
+			sizeInBytes:      0,
+			monitoredObjects: 1000000,
+			split:            IntelligentTieringSplit{FrequentAccess: 1},
+			expected:         2.5,
+		},
+		{ // 100 GB in Frequent Access
+			sizeInBytes:      100 * 1024 * 1024 * 1024,
+			monitoredObjects: 0,
+			split:            IntelligentTieringSplit{FrequentAccess: 1},
+			expected:         round2(0.023 * 100),
+		},
+		{ // 100 GB split between Infrequent Access and Archive Instant Access
+			sizeInBytes:      100 * 1024 * 1024 * 1024,
+			monitoredObjects: 0,
+			split:            IntelligentTieringSplit{InfrequentAccess: 0.5, ArchiveInstantAccess: 0.5},
+			expected:         round2(0.0125*50 + 0.004*50),
+		},
+		{ // 10 GB of objects under 128 KB stay in Frequent Access, the split applies to the other 90 GB
+			sizeInBytes:      100 * 1024 * 1024 * 1024,
+			monitoredObjects: 0,
+			smallObjectsSize: 10 * 1024 * 1024 * 1024,
+			split:            IntelligentTieringSplit{ArchiveInstantAccess: 1},
+			expected:         round2(0.023*10 + 0.004*90),
+		},
+	}
+
+	for _, c := range cases {
+		got, err := Pricing{}.CalculateIntelligentTieringCost("us-east-1", c.sizeInBytes, c.monitoredObjects, c.smallObjectsSize, c.sizeInBytes, c.split)
+		if err != nil {
+			t.Errorf("CalculateIntelligentTieringCost(%d, %d, %v) returned an error: %s", c.sizeInBytes, c.monitoredObjects, c.split, err)
+		}
+		if got != c.expected {
+			t.Errorf("CalculateIntelligentTieringCost(%d, %d, %v) == %v, want %v", c.sizeInBytes, c.monitoredObjects, c.split, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Rank of the storage types in the lifecycle waterfall, objects can only transition to a higher rank
+// (https://docs.aws.amazon.com/AmazonS3/latest/userguide/lifecycle-transition-general-considerations.html)
+var lifecycleRanks = map[string]int{
+	"STANDARD":            0,
+	"REDUCED_REDUNDANCY":  0,
+	"STANDARD_IA":         1,
+	"INTELLIGENT_TIERING": 2,
+	"ONEZONE_IA":          3,
+	"GLACIER_IR":          4,
+	"GLACIER":             5,
+	"DEEP_ARCHIVE":        6,
+}
+
+// Minimum number of days billed for the objects of a storage type, deleting or transitioning them
+// earlier is charged the remaining days
+var minimumStorageDays = map[string]int{
+	"STANDARD_IA":  30,
+	"ONEZONE_IA":   30,
+	"GLACIER_IR":   90,
+	"GLACIER":      90,
+	"DEEP_ARCHIVE": 180,
+}
+
+// LoadLifecycleFile reads a lifecycle configuration in the PutBucketLifecycleConfiguration shape
+// such as {"Rules": [{"ID": "archive", "Status": "Enabled", "Filter": {"Prefix": "logs/"},
+// "Transitions": [{"Days": 30, "StorageClass": "STANDARD_IA"}], "Expiration": {"Days": 365}}]}
+func LoadLifecycleFile(path string) (*s3types.BucketLifecycleConfiguration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration := &s3types.BucketLifecycleConfiguration{}
+	if err := json.Unmarshal(data, configuration); err != nil {
+		return nil, fmt.Errorf("invalid lifecycle file: %w", err)
+	}
+
+	for i, rule := range configuration.Rules {
+		if rule.Status != s3types.ExpirationStatusEnabled && rule.Status != s3types.ExpirationStatusDisabled {
+			return nil, fmt.Errorf("invalid lifecycle file: rule %v has an invalid status %q", LifecycleRuleName(rule, i), rule.Status)
+		}
+		for _, transition := range rule.Transitions {
+			if _, ok := lifecycleRanks[string(transition.StorageClass)]; !ok {
+				return nil, fmt.Errorf("invalid lifecycle file: rule %v transitions to an invalid storage class %q", LifecycleRuleName(rule, i), transition.StorageClass)
+			}
+		}
+	}
+
+	return configuration, nil
+}
+
+// LifecycleRuleName returns the ID of a rule, or its position when it has none
+func LifecycleRuleName(rule s3types.LifecycleRule, index int) string {
+	if id := aws.ToString(rule.ID); id != "" {
+		return id
+	}
+	return fmt.Sprintf("#%v", index+1)
+}
+
+// LifecycleRuleHasTagFilter tells if the objects need their tags to be matched against the rule
+func LifecycleRuleHasTagFilter(rule s3types.LifecycleRule) bool {
+	if rule.Filter == nil {
+		return false
+	}
+	return rule.Filter.Tag != nil || (rule.Filter.And != nil && len(rule.Filter.And.Tags) > 0)
+}
+
+// LifecycleRuleHasSizeFilter tells if the rule sets an object size range, objects under 128 KB are
+// only transitioned by such rules
+func LifecycleRuleHasSizeFilter(rule s3types.LifecycleRule) bool {
+	if rule.Filter == nil {
+		return false
+	}
+	if rule.Filter.ObjectSizeGreaterThan != nil || rule.Filter.ObjectSizeLessThan != nil {
+		return true
+	}
+	return rule.Filter.And != nil && (rule.Filter.And.ObjectSizeGreaterThan != nil || rule.Filter.And.ObjectSizeLessThan != nil)
+}
+
+// LifecycleRuleMatches tells if an object is selected by the filter of a rule
+func LifecycleRuleMatches(rule s3types.LifecycleRule, key string, size int, tags map[string]string) bool {
+	// Rules written before filters were introduced use a prefix at the top level
+	if rule.Filter == nil {
+		return strings.HasPrefix(key, aws.ToString(rule.Prefix))
+	}
+
+	filter := rule.Filter
+	if filter.And != nil {
+		return matchesLifecycleFilter(key, size, tags, filter.And.Prefix, filter.And.ObjectSizeGreaterThan, filter.And.ObjectSizeLessThan, filter.And.Tags)
+	}
+
+	var filterTags []s3types.Tag
+	if filter.Tag != nil {
+		filterTags = []s3types.Tag{*filter.Tag}
+	}
+	return matchesLifecycleFilter(key, size, tags, filter.Prefix, filter.ObjectSizeGreaterThan, filter.ObjectSizeLessThan, filterTags)
+}
+
+func matchesLifecycleFilter(key string, size int, tags map[string]string, prefix *string, greaterThan, lessThan *int64, filterTags []s3types.Tag) bool {
+	if !strings.HasPrefix(key, aws.ToString(prefix)) {
+		return false
+	}
+	if greaterThan != nil && int64(size) <= *greaterThan {
+		return false
+	}
+	if lessThan != nil && int64(size) >= *lessThan {
+		return false
+	}
+	for _, tag := range filterTags {
+		value, ok := tags[aws.ToString(tag.Key)]
+		if !ok || value != aws.ToString(tag.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// LifecycleActionDue tells if a transition or expiration set with a number of days since creation
+// or a date applies to an object of the given age
+func LifecycleActionDue(days *int32, date *time.Time, ageInDays int, now time.Time) bool {
+	if days != nil {
+		return ageInDays >= int(*days)
+	}
+	return date != nil && !date.After(now)
+}
+
+// CanTransition tells if lifecycle rules can move objects from a storage type to another
+func CanTransition(from, to string) bool {
+	fromRank, ok := lifecycleRanks[from]
+	if !ok {
+		return false
+	}
+	toRank, ok := lifecycleRanks[to]
+	return ok && toRank > fromRank
+}
+
+// MinimumStorageDays returns the minimum number of days billed for the objects of a storage type
+func MinimumStorageDays(storageType string) int {
+	return minimumStorageDays[storageType]
+}
+
+// CalculateEarlyDeletionCost prices the remaining days of the minimum storage duration of objects
+// leaving a storage type after ageInDays, using their monthly rate
+func (p Pricing) CalculateEarlyDeletionCost(storageType, region string, sizeInBytes, ageInDays int) (float64, error) {
+	remainingDays := MinimumStorageDays(storageType) - ageInDays
+	if remainingDays <= 0 || sizeInBytes == 0 {
+		return 0.0, nil
+	}
+
+	monthlyCost, err := p.calculateSharedCost(storageType, region, float64(sizeInBytes), float64(sizeInBytes))
+	if err != nil {
+		return 0.0, err
+	}
+	return monthlyCost * float64(remainingDays) / 30, nil
+}
+
+// CalculateMinimumDurationCost prices the days left before objects reach the minimum storage duration
+// of their storage type, given as the sum of their sizes times their remaining days. It is the charge
+// billed if they were all deleted or transitioned now, on top of the monthly cost.
+func (p Pricing) CalculateMinimumDurationCost(storageType, region string, remainingByteDays int) (float64, error) {
+	if MinimumStorageDays(storageType) == 0 || remainingByteDays == 0 {
+		return 0.0, nil
+	}
+
+	// Keeping a byte 30 more days costs a month of storage
+	byteMonths := float64(remainingByteDays) / 30
+	cost, err := p.calculateSharedCost(storageType, region, byteMonths, byteMonths)
+	if err != nil {
+		return 0.0, err
+	}
+	return round2(cost), nil
+}
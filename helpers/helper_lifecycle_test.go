@@ -0,0 +1,124 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestLoadLifecycleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lifecycle.json")
+	data := `{"Rules": [
+		{"ID": "logs", "Status": "Enabled", "Filter": {"And": {"Prefix": "logs/", "ObjectSizeGreaterThan": 1024, "Tags": [{"Key": "archive", "Value": "true"}]}},
+			"Transitions": [{"Days": 30, "StorageClass": "STANDARD_IA"}, {"Days": 90, "StorageClass": "GLACIER"}], "Expiration": {"Days": 365}},
+		{"Status": "Enabled", "Prefix": "tmp/", "Expiration": {"Date": "2024-01-01T00:00:00Z"}}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configuration, err := LoadLifecycleFile(path)
+	if err != nil {
+		t.Fatalf("LoadLifecycleFile() returned an error: %s", err)
+	}
+
+	rule := configuration.Rules[0]
+	if LifecycleRuleName(rule, 0) != "logs" || LifecycleRuleName(configuration.Rules[1], 1) != "#2" {
+		t.Errorf("LifecycleRuleName() == %s, %s, want logs, #2", LifecycleRuleName(rule, 0), LifecycleRuleName(configuration.Rules[1], 1))
+	}
+
+	cases := []struct {
+		key      string
+		size     int
+		tags     map[string]string
+		expected bool
+	}{
+		{key: "logs/a", size: 2048, tags: map[string]string{"archive": "true"}, expected: true},
+		{key: "logs/a", size: 2048, tags: nil, expected: false},
+		{key: "logs/a", size: 512, tags: map[string]string{"archive": "true"}, expected: false},
+		{key: "data/a", size: 2048, tags: map[string]string{"archive": "true"}, expected: false},
+	}
+	for _, c := range cases {
+		if got := LifecycleRuleMatches(rule, c.key, c.size, c.tags); got != c.expected {
+			t.Errorf("LifecycleRuleMatches(%s, %d, %v) == %v, want %v", c.key, c.size, c.tags, got, c.expected)
+		}
+	}
+
+	if !LifecycleRuleMatches(configuration.Rules[1], "tmp/a", 0, nil) || LifecycleRuleMatches(configuration.Rules[1], "data/a", 0, nil) {
+		t.Errorf("LifecycleRuleMatches() does not use the legacy prefix")
+	}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !LifecycleActionDue(nil, configuration.Rules[1].Expiration.Date, 0, now) {
+		t.Errorf("LifecycleActionDue() == false for a past date")
+	}
+	if LifecycleActionDue(aws.Int32(30), nil, 29, now) || !LifecycleActionDue(aws.Int32(30), nil, 30, now) {
+		t.Errorf("LifecycleActionDue() does not count the days since creation")
+	}
+}
+
+func TestLoadLifecycleFileInvalidStorageClass(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lifecycle.json")
+	data := `{"Rules": [{"Status": "Enabled", "Transitions": [{"Days": 30, "StorageClass": "COLD"}]}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadLifecycleFile(path); err == nil {
+		t.Errorf("LoadLifecycleFile() accepted an invalid storage class")
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from     string
+		to       string
+		expected bool
+	}{
+		{from: "STANDARD", to: "STANDARD_IA", expected: true},
+		{from: "STANDARD_IA", to: "INTELLIGENT_TIERING", expected: true},
+		{from: "INTELLIGENT_TIERING", to: "STANDARD_IA", expected: false},
+		{from: "GLACIER", to: "DEEP_ARCHIVE", expected: true},
+		{from: "DEEP_ARCHIVE", to: "GLACIER", expected: false},
+		{from: "STANDARD", to: "STANDARD", expected: false},
+	}
+
+	for _, c := range cases {
+		if got := CanTransition(c.from, c.to); got != c.expected {
+			t.Errorf("CanTransition(%s, %s) == %v, want %v", c.from, c.to, got, c.expected)
+		}
+	}
+}
+
+func TestCalculateEarlyDeletionCost(t *testing.T) {
+	// 100 GB of GLACIER deleted after 30 days, 60 of the 90 days remain: 2 months at $0.36
+	cost, err := Pricing{}.CalculateEarlyDeletionCost("GLACIER", "us-east-1", 100*testGB, 30)
+	if err != nil {
+		t.Fatalf("CalculateEarlyDeletionCost() returned an error: %s", err)
+	}
+	if round2(cost) != 0.72 {
+		t.Errorf("CalculateEarlyDeletionCost() == %v, want 0.72", cost)
+	}
+
+	if cost, _ := (Pricing{}).CalculateEarlyDeletionCost("STANDARD", "us-east-1", 100*testGB, 0); cost != 0 {
+		t.Errorf("CalculateEarlyDeletionCost(STANDARD) == %v, want 0", cost)
+	}
+}
+
+func TestCalculateMinimumDurationCost(t *testing.T) {
+	// The same 100 GB of GLACIER with 60 days left
+	cost, err := Pricing{}.CalculateMinimumDurationCost("GLACIER", "us-east-1", 100*testGB*60)
+	if err != nil {
+		t.Fatalf("CalculateMinimumDurationCost() returned an error: %s", err)
+	}
+	if cost != 0.72 {
+		t.Errorf("CalculateMinimumDurationCost() == %v, want 0.72", cost)
+	}
+
+	if cost, _ := (Pricing{}).CalculateMinimumDurationCost("STANDARD", "us-east-1", 100*testGB*60); cost != 0 {
+		t.Errorf("CalculateMinimumDurationCost(STANDARD) == %v, want 0", cost)
+	}
+}
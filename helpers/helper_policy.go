@@ -0,0 +1,224 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Policy is a bucket policy document
+// (https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_policies_elements.html)
+type Policy struct {
+	Version   string
+	ID        string `json:"Id"`
+	Statement PolicyStatements
+}
+
+type PolicyStatement struct {
+	Sid          string
+	Effect       string
+	Principal    *PolicyPrincipal
+	NotPrincipal *PolicyPrincipal
+	Action       PolicyValues
+	NotAction    PolicyValues
+	Resource     PolicyValues
+	NotResource  PolicyValues
+	// Condition operator (e.g. Bool, StringEquals) to condition key to values
+	Condition map[string]map[string]PolicyValues
+}
+
+// PolicyStatements accepts a single statement as well as a list
+type PolicyStatements []PolicyStatement
+
+// PolicyValues accepts a single string as well as a list, booleans and numbers of the conditions
+// are kept as strings
+type PolicyValues []string
+
+// PolicyPrincipal holds the principals per type (AWS, Service, Federated, CanonicalUser), the
+// anonymous principal "*" is stored under the "*" type
+type PolicyPrincipal map[string]PolicyValues
+
+var accountIDPattern = regexp.MustCompile(`^\d{12}$`)
+
+// ParsePolicy reads a policy document such as {"Version": "2012-10-17", "Statement": [{"Sid":
+// "public", "Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::bucket/*"}]}
+func ParsePolicy(document string) (Policy, error) {
+	policy := Policy{}
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return policy, fmt.Errorf("invalid policy: %w", err)
+	}
+
+	for i, statement := range policy.Statement {
+		if statement.Effect != "Allow" && statement.Effect != "Deny" {
+			return policy, fmt.Errorf("invalid policy: statement %v has an invalid effect %q", PolicyStatementName(statement, i), statement.Effect)
+		}
+	}
+	return policy, nil
+}
+
+// LoadPolicyFile reads a policy document from a file, see ParsePolicy
+func LoadPolicyFile(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+	return ParsePolicy(string(data))
+}
+
+// PolicyStatementName returns the Sid of a statement, or its position like #2 when it has none
+func PolicyStatementName(statement PolicyStatement, index int) string {
+	if statement.Sid != "" {
+		return statement.Sid
+	}
+	return fmt.Sprintf("#%v", index+1)
+}
+
+func (s *PolicyStatements) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		statement := PolicyStatement{}
+		if err := json.Unmarshal(data, &statement); err != nil {
+			return err
+		}
+		*s = PolicyStatements{statement}
+		return nil
+	}
+
+	statements := []PolicyStatement{}
+	if err := json.Unmarshal(data, &statements); err != nil {
+		return err
+	}
+	*s = statements
+	return nil
+}
+
+func (v *PolicyValues) UnmarshalJSON(data []byte) error {
+	raw := []any{}
+	if len(data) > 0 && data[0] != '[' {
+		var value any
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		raw = append(raw, value)
+	} else if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	values := PolicyValues{}
+	for _, value := range raw {
+		switch value := value.(type) {
+		case string:
+			values = append(values, value)
+		case bool, float64:
+			values = append(values, fmt.Sprint(value))
+		default:
+			return fmt.Errorf("invalid policy value %v", value)
+		}
+	}
+	*v = values
+	return nil
+}
+
+func (p *PolicyPrincipal) UnmarshalJSON(data []byte) error {
+	var anonymous string
+	if err := json.Unmarshal(data, &anonymous); err == nil {
+		*p = PolicyPrincipal{anonymous: PolicyValues{anonymous}}
+		return nil
+	}
+
+	principals := map[string]PolicyValues{}
+	if err := json.Unmarshal(data, &principals); err != nil {
+		return err
+	}
+	*p = principals
+	return nil
+}
+
+// IsWildcard tells if the principal matches anyone, either "*" or {"AWS": "*"}
+func (p PolicyPrincipal) IsWildcard() bool {
+	return slices.Contains(p["*"], "*") || slices.Contains(p["AWS"], "*")
+}
+
+// Accounts returns the account IDs of the AWS principals, given either as an ID or as an ARN
+func (p PolicyPrincipal) Accounts() []string {
+	accounts := []string{}
+	for _, principal := range p["AWS"] {
+		if accountIDPattern.MatchString(principal) {
+			accounts = append(accounts, principal)
+			continue
+		}
+
+		// arn:aws:iam::111122223333:root, arn:aws:iam::111122223333:role/name...
+		parts := strings.Split(principal, ":")
+		if len(parts) >= 6 && parts[0] == "arn" && accountIDPattern.MatchString(parts[4]) {
+			accounts = append(accounts, parts[4])
+		}
+	}
+	return accounts
+}
+
+// MatchesAction tells if the statement covers an action, using the wildcards of the Action element
+// or the exclusions of the NotAction element
+func (s PolicyStatement) MatchesAction(action string) bool {
+	if len(s.NotAction) > 0 {
+		return !slices.ContainsFunc(s.NotAction, func(pattern string) bool {
+			return matchesWildcard(pattern, action)
+		})
+	}
+	return slices.ContainsFunc(s.Action, func(pattern string) bool {
+		return matchesWildcard(pattern, action)
+	})
+}
+
+// ConditionKeys returns the condition keys of the statement, e.g. aws:SourceVpce
+func (s PolicyStatement) ConditionKeys() []string {
+	keys := []string{}
+	for _, conditions := range s.Condition {
+		for key := range conditions {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// ConditionValues returns the values of a condition key for the operators matching a prefix (e.g.
+// Bool also matches BoolIfExists), keys being case insensitive like in IAM
+func (s PolicyStatement) ConditionValues(operator, key string) []string {
+	values := []string{}
+	for name, conditions := range s.Condition {
+		if !strings.HasPrefix(name, operator) {
+			continue
+		}
+		for conditionKey, conditionValues := range conditions {
+			if strings.EqualFold(conditionKey, key) {
+				values = append(values, conditionValues...)
+			}
+		}
+	}
+	return values
+}
+
+// matchesWildcard matches a value against a pattern using * and ?, case insensitively like IAM actions
+func matchesWildcard(pattern, value string) bool {
+	pattern, value = strings.ToLower(pattern), strings.ToLower(value)
+	if pattern == "" {
+		return value == ""
+	}
+
+	switch pattern[0] {
+	case '*':
+		for i := 0; i <= len(value); i++ {
+			if matchesWildcard(pattern[1:], value[i:]) {
+				return true
+			}
+		}
+		return false
+	case '?':
+		return value != "" && matchesWildcard(pattern[1:], value[1:])
+	default:
+		return value != "" && pattern[0] == value[0] && matchesWildcard(pattern[1:], value[1:])
+	}
+}
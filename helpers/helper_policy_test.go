@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy(`{"Version": "2012-10-17", "Id": "example", "Statement": [
+		{"Sid": "Read", "Effect": "Allow", "Principal": "*", "Action": "s3:Get*", "Resource": "arn:aws:s3:::bucket/*"},
+		{"Effect": "Allow", "Principal": {"AWS": ["arn:aws:iam::111122223333:role/reader", "444455556666"], "Service": "cloudtrail.amazonaws.com"},
+			"NotAction": "s3:Delete*", "Resource": "*", "Condition": {"BoolIfExists": {"aws:SecureTransport": "true"}, "NumericLessThan": {"s3:max-keys": 10}}}
+	]}`)
+	if err != nil {
+		t.Fatalf("ParsePolicy() returned an error: %s", err)
+	}
+
+	read, other := policy.Statement[0], policy.Statement[1]
+	if PolicyStatementName(read, 0) != "Read" || PolicyStatementName(other, 1) != "#2" {
+		t.Errorf("PolicyStatementName() == %s, %s, want Read, #2", PolicyStatementName(read, 0), PolicyStatementName(other, 1))
+	}
+
+	if !read.Principal.IsWildcard() || other.Principal.IsWildcard() {
+		t.Errorf("IsWildcard() == %v, %v, want true, false", read.Principal.IsWildcard(), other.Principal.IsWildcard())
+	}
+	if accounts := other.Principal.Accounts(); !slices.Equal(accounts, []string{"111122223333", "444455556666"}) {
+		t.Errorf("Accounts() == %v, want [111122223333 444455556666]", accounts)
+	}
+
+	cases := []struct {
+		statement PolicyStatement
+		action    string
+		expected  bool
+	}{
+		{statement: read, action: "s3:GetObject", expected: true},
+		{statement: read, action: "S3:GETOBJECTACL", expected: true},
+		{statement: read, action: "s3:PutObject", expected: false},
+		{statement: other, action: "s3:PutObject", expected: true},
+		{statement: other, action: "s3:DeleteObject", expected: false},
+	}
+	for _, c := range cases {
+		if result := c.statement.MatchesAction(c.action); result != c.expected {
+			t.Errorf("MatchesAction(%s) == %v, want %v", c.action, result, c.expected)
+		}
+	}
+
+	if values := other.ConditionValues("Bool", "aws:securetransport"); !slices.Equal(values, []string{"true"}) {
+		t.Errorf("ConditionValues() == %v, want [true]", values)
+	}
+	if keys := other.ConditionKeys(); !slices.Equal(keys, []string{"aws:SecureTransport", "s3:max-keys"}) {
+		t.Errorf("ConditionKeys() == %v, want [aws:SecureTransport s3:max-keys]", keys)
+	}
+
+	if _, err := ParsePolicy(`{"Statement": [{"Effect": "Maybe"}]}`); err == nil {
+		t.Errorf("ParsePolicy() accepted an invalid effect")
+	}
+}
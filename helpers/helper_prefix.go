@@ -0,0 +1,28 @@
+package helpers
+
+import "strings"
+
+// KeyPrefixes returns the prefixes of a key down to the given depth, each one including its
+// delimiter, e.g. [logs/ logs/2024/] for logs/2024/app.log with a depth of 2
+func KeyPrefixes(key string, depth int, delimiter string) []string {
+	prefixes := []string{}
+	end := 0
+	for len(prefixes) < depth {
+		index := strings.Index(key[end:], delimiter)
+		if index == -1 {
+			break
+		}
+		end += index + len(delimiter)
+		prefixes = append(prefixes, key[:end])
+	}
+	return prefixes
+}
+
+// TopLevelPrefix returns the first level of a key including its delimiter, e.g. logs/ for
+// logs/2024/app.log, and an empty string for the keys at the root of the bucket
+func TopLevelPrefix(key, delimiter string) string {
+	if index := strings.Index(key, delimiter); index != -1 {
+		return key[:index+len(delimiter)]
+	}
+	return ""
+}
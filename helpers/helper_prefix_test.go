@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKeyPrefixes(t *testing.T) {
+	cases := []struct {
+		depth     int
+		delimiter string
+		key       string
+		expected  []string
+	}{
+		{depth: 2, delimiter: "/", key: "logs/2024/01/app.log", expected: []string{"logs/", "logs/2024/"}},
+		{depth: 2, delimiter: "/", key: "logs/app.log", expected: []string{"logs/"}},
+		{depth: 2, delimiter: "/", key: "app.log", expected: []string{}},
+		{depth: 0, delimiter: "/", key: "logs/app.log", expected: []string{}},
+		{depth: 3, delimiter: "--", key: "team--a--b--c", expected: []string{"team--", "team--a--", "team--a--b--"}},
+	}
+
+	for _, c := range cases {
+		if got := KeyPrefixes(c.key, c.depth, c.delimiter); !slices.Equal(got, c.expected) {
+			t.Errorf("KeyPrefixes(%s) with depth %d == %v, want %v", c.key, c.depth, got, c.expected)
+		}
+	}
+}
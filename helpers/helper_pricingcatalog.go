@@ -0,0 +1,247 @@
+package helpers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed data/pricing.json
+var defaultPricingCatalog []byte
+
+// Storage classes every region group of a catalog must describe, either with rates or as unavailable
+var catalogStorageClasses = []string{
+	"STANDARD",
+	"REDUCED_REDUNDANCY",
+	"STANDARD_IA",
+	"ONEZONE_IA",
+	"GLACIER",
+	"GLACIER_IR",
+	"DEEP_ARCHIVE",
+	"EXPRESS_ONEZONE",
+	"INTELLIGENT_TIERING_FREQUENT_ACCESS",
+	"INTELLIGENT_TIERING_INFREQUENT_ACCESS",
+	"INTELLIGENT_TIERING_ARCHIVE_INSTANT_ACCESS",
+	"INTELLIGENT_TIERING_ARCHIVE_ACCESS",
+	"INTELLIGENT_TIERING_DEEP_ARCHIVE_ACCESS",
+}
+
+// PricingCatalog describes the monthly storage rates per region group and storage class
+type PricingCatalog struct {
+	Version      string               `json:"version"`
+	Currency     string               `json:"currency"`
+	RegionGroups []RegionGroupPricing `json:"regionGroups"`
+
+	regions map[string]*RegionGroupPricing
+}
+
+type RegionGroupPricing struct {
+	Name    string   `json:"name"`
+	Regions []string `json:"regions"`
+	// Intelligent-Tiering monitoring and automation fee, only objects of 128 KB and more are monitored
+	IntelligentTieringMonitoringPer1000Objects float64 `json:"intelligentTieringMonitoringPer1000Objects"`
+	// Lifecycle transitions into Intelligent-Tiering, billed above the Frequent Access PUT rate
+	IntelligentTieringTransitionsPer1000 float64                        `json:"intelligentTieringTransitionsPer1000,omitempty"`
+	StorageClasses                       map[string]StorageClassPricing `json:"storageClasses"`
+}
+
+type StorageClassPricing struct {
+	// The storage class cannot be used in the regions of the group
+	Unavailable bool        `json:"unavailable,omitempty"`
+	Tiers       []PriceTier `json:"tiers,omitempty"`
+
+	// Price per 1,000 PUT, COPY, POST and LIST requests
+	Tier1RequestsPer1000 float64 `json:"tier1RequestsPer1000,omitempty"`
+	// Price per 1,000 GET, SELECT and other requests
+	Tier2RequestsPer1000 float64 `json:"tier2RequestsPer1000,omitempty"`
+	// Price per GB retrieved, for the storage classes that bill retrievals
+	RetrievalPerGB float64 `json:"retrievalPerGB,omitempty"`
+}
+
+// PriceTier applies its rate up to UpToGB (cumulated over the previous tiers).
+// The last tier has no upper bound and leaves UpToGB empty.
+type PriceTier struct {
+	UpToGB     float64 `json:"upToGB,omitempty"`
+	PricePerGB float64 `json:"pricePerGB"`
+}
+
+// Catalog embedded in the binary, it is never modified once parsed
+var embeddedPricingCatalog *PricingCatalog
+
+func init() {
+	catalog, err := ParsePricingCatalog(defaultPricingCatalog)
+	if err != nil {
+		panic(fmt.Errorf("invalid embedded pricing catalog: %w", err))
+	}
+	embeddedPricingCatalog = catalog
+}
+
+// Pricing holds the rates and the assumptions used by the cost calculations
+type Pricing struct {
+	// Rates of the storage classes, the embedded catalog when nil
+	Catalog *PricingCatalog
+	// Split provided by the user, when nil the split is inferred from each bucket's objects
+	IntelligentTieringSplit IntelligentTieringSplit
+}
+
+func (p Pricing) catalog() *PricingCatalog {
+	if p.Catalog == nil {
+		return embeddedPricingCatalog
+	}
+	return p.Catalog
+}
+
+func LoadPricingFile(path string) (*PricingCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePricingCatalog(data)
+}
+
+func ParsePricingCatalog(data []byte) (*PricingCatalog, error) {
+	catalog := &PricingCatalog{}
+	if err := json.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("invalid pricing catalog: %w", err)
+	}
+
+	if err := catalog.validate(); err != nil {
+		return nil, err
+	}
+
+	return catalog, nil
+}
+
+func (c *PricingCatalog) validate() error {
+	if c.Version == "" {
+		return fmt.Errorf("pricing catalog has no version")
+	}
+
+	c.regions = map[string]*RegionGroupPricing{}
+	for i := range c.RegionGroups {
+		group := &c.RegionGroups[i]
+
+		if len(group.Regions) == 0 {
+			return fmt.Errorf("region group %v has no regions", group.Name)
+		}
+
+		for _, region := range group.Regions {
+			if _, ok := c.regions[region]; ok {
+				return fmt.Errorf("region %v is defined in more than one region group", region)
+			}
+			c.regions[region] = group
+		}
+
+		if group.IntelligentTieringMonitoringPer1000Objects <= 0 && !group.StorageClasses["INTELLIGENT_TIERING_FREQUENT_ACCESS"].Unavailable {
+			return fmt.Errorf("region group %v is missing the Intelligent-Tiering monitoring fee", group.Name)
+		}
+
+		for _, storageType := range catalogStorageClasses {
+			pricing, ok := group.StorageClasses[storageType]
+			if !ok {
+				return fmt.Errorf("region group %v is missing the rates for %v", group.Name, storageType)
+			}
+
+			if err := pricing.validate(); err != nil {
+				return fmt.Errorf("region group %v, %v: %w", group.Name, storageType, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p StorageClassPricing) validate() error {
+	if p.Unavailable {
+		if len(p.Tiers) > 0 {
+			return fmt.Errorf("unavailable storage class should not have tiers")
+		}
+		return nil
+	}
+
+	if len(p.Tiers) == 0 {
+		return fmt.Errorf("no tiers")
+	}
+
+	previousUpTo := 0.0
+	for i, tier := range p.Tiers {
+		if tier.PricePerGB <= 0 {
+			return fmt.Errorf("tier %v has no price", i+1)
+		}
+
+		if i == len(p.Tiers)-1 {
+			if tier.UpToGB != 0 {
+				return fmt.Errorf("last tier should not have an upper bound")
+			}
+			break
+		}
+
+		if tier.UpToGB <= previousUpTo {
+			return fmt.Errorf("tier %v upper bound should be greater than the previous one", i+1)
+		}
+		previousUpTo = tier.UpToGB
+	}
+
+	return nil
+}
+
+// StorageClass returns the rates of a storage class in the given region
+func (c *PricingCatalog) StorageClass(region, storageType string) (StorageClassPricing, error) {
+	group, ok := c.regions[region]
+	if !ok {
+		return StorageClassPricing{}, fmt.Errorf("no pricing for region %v", region)
+	}
+
+	pricing, ok := group.StorageClasses[storageType]
+	if !ok {
+		return StorageClassPricing{}, fmt.Errorf("no pricing for storage type %v in region %v", storageType, region)
+	}
+
+	if pricing.Unavailable {
+		return StorageClassPricing{}, fmt.Errorf("%v is not available in region %v", storageType, region)
+	}
+
+	return pricing, nil
+}
+
+// IsStorageClassAvailable tells if the catalog has rates for a storage type in a region
+func (p Pricing) IsStorageClassAvailable(storageType, region string) bool {
+	_, err := p.catalog().StorageClass(region, storageType)
+	return err == nil
+}
+
+func (c *PricingCatalog) IntelligentTieringMonitoringFee(region string) (float64, error) {
+	group, ok := c.regions[region]
+	if !ok {
+		return 0.0, fmt.Errorf("no pricing for region %v", region)
+	}
+
+	return group.IntelligentTieringMonitoringPer1000Objects, nil
+}
+
+func (c *PricingCatalog) IntelligentTieringTransitionFee(region string) (float64, error) {
+	group, ok := c.regions[region]
+	if !ok {
+		return 0.0, fmt.Errorf("no pricing for region %v", region)
+	}
+
+	return group.IntelligentTieringTransitionsPer1000, nil
+}
+
+// Cost applies the tiers to the given size
+func (p StorageClassPricing) Cost(sizeInGB float64) float64 {
+	totalCost := 0.0
+	previousUpTo := 0.0
+	for _, tier := range p.Tiers {
+		if tier.UpToGB == 0 || sizeInGB <= tier.UpToGB {
+			totalCost += tier.PricePerGB * (sizeInGB - previousUpTo)
+			break
+		}
+
+		totalCost += tier.PricePerGB * (tier.UpToGB - previousUpTo)
+		previousUpTo = tier.UpToGB
+	}
+	return totalCost
+}
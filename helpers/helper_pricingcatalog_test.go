@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultPricingCatalog(t *testing.T) {
+	catalog, err := ParsePricingCatalog(defaultPricingCatalog)
+	if err != nil {
+		t.Fatalf("ParsePricingCatalog(default) returned an error: %s", err)
+	}
+
+	cases := []struct {
+		region      string
+		storageType string
+		expectErr   bool
+	}{
+		{region: "us-east-1", storageType: "STANDARD", expectErr: false},
+		{region: "mx-central-1", storageType: "GLACIER", expectErr: false},
+		{region: "us-gov-west-1", storageType: "ONEZONE_IA", expectErr: false},
+		{region: "af-south-1", storageType: "DEEP_ARCHIVE", expectErr: false},
+		{region: "ca-central-1", storageType: "EXPRESS_ONEZONE", expectErr: true},
+		{region: "moon-central-1", storageType: "STANDARD", expectErr: true},
+	}
+
+	for _, c := range cases {
+		_, err := catalog.StorageClass(c.region, c.storageType)
+		if (err != nil) != c.expectErr {
+			t.Errorf("StorageClass(%s, %s) returned error %v, expected an error: %v", c.region, c.storageType, err, c.expectErr)
+		}
+	}
+}
+
+func TestDefaultPricingCatalogRegions(t *testing.T) {
+	catalog, err := ParsePricingCatalog(defaultPricingCatalog)
+	if err != nil {
+		t.Fatalf("ParsePricingCatalog(default) returned an error: %s", err)
+	}
+
+	regions := []string{
+		"us-east-1", "us-east-2", "us-west-1", "us-west-2",
+		"ca-central-1", "ca-west-1", "mx-central-1", "sa-east-1",
+		"us-gov-east-1", "us-gov-west-1",
+		"eu-west-1", "eu-west-2", "eu-west-3", "eu-central-1", "eu-central-2", "eu-north-1", "eu-south-1", "eu-south-2",
+		"il-central-1", "me-south-1", "me-central-1", "af-south-1",
+		"ap-east-1", "ap-south-1", "ap-south-2", "ap-northeast-1", "ap-northeast-2", "ap-northeast-3",
+		"ap-southeast-1", "ap-southeast-2", "ap-southeast-3", "ap-southeast-4", "ap-southeast-5", "ap-southeast-7",
+	}
+
+	for _, region := range regions {
+		if _, err := catalog.StorageClass(region, "STANDARD"); err != nil {
+			t.Errorf("StorageClass(%s, STANDARD) returned an error: %s", region, err)
+		}
+	}
+}
+
+func TestParsePricingCatalogValidation(t *testing.T) {
+	// Every storage class but STANDARD, which is added by each case
+	otherClasses := `
+		"REDUCED_REDUNDANCY": {"tiers": [{"pricePerGB": 0.024}]},
+		"STANDARD_IA": {"tiers": [{"pricePerGB": 0.0125}]},
+		"ONEZONE_IA": {"tiers": [{"pricePerGB": 0.01}]},
+		"GLACIER": {"tiers": [{"pricePerGB": 0.0036}]},
+		"GLACIER_IR": {"tiers": [{"pricePerGB": 0.004}]},
+		"DEEP_ARCHIVE": {"tiers": [{"pricePerGB": 0.00099}]},
+		"EXPRESS_ONEZONE": {"unavailable": true},
+		"INTELLIGENT_TIERING_FREQUENT_ACCESS": {"tiers": [{"pricePerGB": 0.023}]},
+		"INTELLIGENT_TIERING_INFREQUENT_ACCESS": {"tiers": [{"pricePerGB": 0.0125}]},
+		"INTELLIGENT_TIERING_ARCHIVE_INSTANT_ACCESS": {"tiers": [{"pricePerGB": 0.004}]},
+		"INTELLIGENT_TIERING_ARCHIVE_ACCESS": {"tiers": [{"pricePerGB": 0.0036}]},
+		"INTELLIGENT_TIERING_DEEP_ARCHIVE_ACCESS": {"tiers": [{"pricePerGB": 0.00099}]}`
+
+	catalog := func(standard string) string {
+		return `{"version": "test", "currency": "USD", "regionGroups": [{"name": "test", "regions": ["us-east-1"], "intelligentTieringMonitoringPer1000Objects": 0.0025, "storageClasses": {` + standard + otherClasses + `}}]}`
+	}
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    catalog(`"STANDARD": {"tiers": [{"upToGB": 50, "pricePerGB": 0.023}, {"pricePerGB": 0.022}]},`),
+			expected: "",
+		},
+		{ // Missing storage class
+			input:    catalog(``),
+			expected: "missing the rates for STANDARD",
+		},
+		{ // Missing rate
+			input:    catalog(`"STANDARD": {"tiers": [{"upToGB": 50, "pricePerGB": 0.023}, {}]},`),
+			expected: "tier 2 has no price",
+		},
+		{ // Tiers out of order
+			input:    catalog(`"STANDARD": {"tiers": [{"upToGB": 50, "pricePerGB": 0.023}, {"upToGB": 10, "pricePerGB": 0.022}, {"pricePerGB": 0.021}]},`),
+			expected: "tier 2 upper bound",
+		},
+		{ // Last tier bounded
+			input:    catalog(`"STANDARD": {"tiers": [{"upToGB": 50, "pricePerGB": 0.023}]},`),
+			expected: "last tier should not have an upper bound",
+		},
+		{
+			input:    `{"currency": "USD", "regionGroups": []}`,
+			expected: "no version",
+		},
+	}
+
+	for _, c := range cases {
+		_, err := ParsePricingCatalog([]byte(c.input))
+		if c.expected == "" && err != nil {
+			t.Errorf("ParsePricingCatalog(%s) returned an error: %s", c.input, err)
+		}
+		if c.expected != "" && (err == nil || !strings.Contains(err.Error(), c.expected)) {
+			t.Errorf("ParsePricingCatalog(%s) returned error %v, want %s", c.input, err, c.expected)
+		}
+	}
+}
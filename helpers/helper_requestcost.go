@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// RequestCounts is a number of requests per month, split by billing tier
+type RequestCounts struct {
+	// PUT, COPY, POST and LIST requests
+	Tier1 int `json:"tier1"`
+	// GET, SELECT and other requests
+	Tier2 int `json:"tier2"`
+}
+
+func (c RequestCounts) Add(other RequestCounts) RequestCounts {
+	return RequestCounts{
+		Tier1: c.Tier1 + other.Tier1,
+		Tier2: c.Tier2 + other.Tier2,
+	}
+}
+
+// BucketRequests holds the monthly requests per bucket name and storage type. An empty storage
+// type means the storage type of the requests is unknown.
+type BucketRequests map[string]map[string]RequestCounts
+
+func (r BucketRequests) Merge(other BucketRequests) {
+	for bucket, storageTypes := range other {
+		if r[bucket] == nil {
+			r[bucket] = map[string]RequestCounts{}
+		}
+		for storageType, counts := range storageTypes {
+			r[bucket][storageType] = r[bucket][storageType].Add(counts)
+		}
+	}
+}
+
+// LoadRequestsFile reads monthly request counts such as
+// {"my-bucket": {"STANDARD": {"tier1": 100000, "tier2": 2500000}}}
+func LoadRequestsFile(path string) (BucketRequests, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := BucketRequests{}
+	if err := json.Unmarshal(data, &requests); err != nil {
+		return nil, fmt.Errorf("invalid requests file: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (p Pricing) CalculateRequestsCost(storageType, region string, counts RequestCounts) (float64, error) {
+	catalogStorageType := storageType
+	switch storageType {
+	case "INTELLIGENT_TIERING":
+		catalogStorageType = intelligentTieringPrefix + FrequentAccess
+	case "OUTPOSTS", "SNOW":
+		return 0.0, nil
+	}
+
+	pricing, err := p.catalog().StorageClass(region, catalogStorageType)
+	if err != nil {
+		return 0.0, err
+	}
+
+	if pricing.Tier1RequestsPer1000 == 0 || pricing.Tier2RequestsPer1000 == 0 {
+		return 0.0, fmt.Errorf("no request rates for %v in region %v", storageType, region)
+	}
+
+	cost := pricing.Tier1RequestsPer1000*float64(counts.Tier1)/1000 + pricing.Tier2RequestsPer1000*float64(counts.Tier2)/1000
+	return round2(cost), nil
+}
+
+// CalculateTransitionsCost prices the lifecycle transitions of objects into a storage type. They are
+// billed as PUT requests of the target storage type, except for Intelligent-Tiering which has its own
+// rate; catalogs without it, like the imported ones, fall back to the Frequent Access PUT rate.
+func (p Pricing) CalculateTransitionsCost(storageType, region string, objects int) (float64, error) {
+	if storageType == "INTELLIGENT_TIERING" {
+		fee, err := p.catalog().IntelligentTieringTransitionFee(region)
+		if err != nil {
+			return 0.0, err
+		}
+		if fee > 0 {
+			return round2(fee * float64(objects) / 1000), nil
+		}
+	}
+
+	return p.CalculateRequestsCost(storageType, region, RequestCounts{Tier1: objects})
+}
@@ -0,0 +1,62 @@
+package helpers
+
+import "testing"
+
+func TestCalculateRequestsCost(t *testing.T) {
+	counts := RequestCounts{Tier1: 100000, Tier2: 1000000}
+	cases := []struct {
+		storageType string
+		expected    float64
+	}{
+		// $0.005 per 1,000 PUT and $0.0004 per 1,000 GET
+		{storageType: "STANDARD", expected: 0.9},
+		// $0.01 per 1,000 PUT and $0.001 per 1,000 GET
+		{storageType: "STANDARD_IA", expected: 2},
+		// Billed at the Frequent Access rates, the STANDARD ones
+		{storageType: "INTELLIGENT_TIERING", expected: 0.9},
+		// Not billed by S3
+		{storageType: "OUTPOSTS", expected: 0},
+	}
+
+	for _, c := range cases {
+		cost, err := Pricing{}.CalculateRequestsCost(c.storageType, "us-east-1", counts)
+		if err != nil {
+			t.Fatalf("CalculateRequestsCost(%s) returned an error: %s", c.storageType, err)
+		}
+		if cost != c.expected {
+			t.Errorf("CalculateRequestsCost(%s) == %v, want %v", c.storageType, cost, c.expected)
+		}
+	}
+
+	if _, err := (Pricing{}).CalculateRequestsCost("STANDARD", "mars-1", counts); err == nil {
+		t.Errorf("CalculateRequestsCost() returned no error for an unknown region")
+	}
+
+	// The embedded catalog has no request rates outside of the US and Stockholm
+	if _, err := (Pricing{}).CalculateRequestsCost("STANDARD", "eu-central-1", counts); err == nil {
+		t.Errorf("CalculateRequestsCost() returned no error without request rates")
+	}
+}
+
+func TestCalculateTransitionsCost(t *testing.T) {
+	cases := []struct {
+		storageType string
+		expected    float64
+	}{
+		// 100,000 transitions billed as PUT requests of the target storage type
+		{storageType: "STANDARD_IA", expected: 1},
+		{storageType: "GLACIER", expected: 3},
+		// Intelligent-Tiering has its own transition rate, not the Frequent Access PUT rate
+		{storageType: "INTELLIGENT_TIERING", expected: 1},
+	}
+
+	for _, c := range cases {
+		cost, err := Pricing{}.CalculateTransitionsCost(c.storageType, "us-east-1", 100000)
+		if err != nil {
+			t.Fatalf("CalculateTransitionsCost(%s) returned an error: %s", c.storageType, err)
+		}
+		if cost != c.expected {
+			t.Errorf("CalculateTransitionsCost(%s) == %v, want %v", c.storageType, cost, c.expected)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// RuleProfile selects the compliance rules to evaluate, e.g. {"disabled": ["S3.15"]}. When Enabled
+// is set only its rules are evaluated, Disabled is then applied on top.
+type RuleProfile struct {
+	Enabled  []string `json:"enabled"`
+	Disabled []string `json:"disabled"`
+	// Rules added to the built-in ones, enabled like them
+	Rules []RuleDefinition `json:"rules"`
+}
+
+// RuleDefinition describes a rule violated when one of its audit checks does not pass, e.g.
+// {"id": "TEAM.1", "severity": "high", "description": "...", "remediation": "...", "checks": ["encryption", "logging"]}
+type RuleDefinition struct {
+	ID          string   `json:"id"`
+	Severity    string   `json:"severity"`
+	Description string   `json:"description"`
+	Remediation string   `json:"remediation"`
+	Checks      []string `json:"checks"`
+	// Also accept the warnings of the checks
+	AllowWarn bool `json:"allowWarn"`
+}
+
+func LoadRuleProfile(path string) (RuleProfile, error) {
+	profile := RuleProfile{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return profile, err
+	}
+
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return profile, fmt.Errorf("invalid rule profile: %w", err)
+	}
+	return profile, nil
+}
+
+// IsEnabled tells if the profile evaluates a rule, every rule being enabled by the empty profile
+func (p RuleProfile) IsEnabled(id string) bool {
+	if len(p.Enabled) > 0 && !slices.Contains(p.Enabled, id) {
+		return false
+	}
+	return !slices.Contains(p.Disabled, id)
+}
+
+// RuleIDs returns every rule named by the profile
+func (p RuleProfile) RuleIDs() []string {
+	return append(slices.Clone(p.Enabled), p.Disabled...)
+}
@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRuleProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.json")
+	if err := os.WriteFile(path, []byte(`{"enabled": ["CIS-2.1.1", "S3.9"], "disabled": ["S3.9"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := LoadRuleProfile(path)
+	if err != nil {
+		t.Fatalf("LoadRuleProfile() returned an error: %s", err)
+	}
+
+	cases := []struct {
+		id       string
+		expected bool
+	}{
+		{id: "CIS-2.1.1", expected: true},
+		{id: "S3.9", expected: false},
+		{id: "S3.14", expected: false},
+	}
+	for _, c := range cases {
+		if result := profile.IsEnabled(c.id); result != c.expected {
+			t.Errorf("IsEnabled(%s) == %v, want %v", c.id, result, c.expected)
+		}
+	}
+
+	if !(RuleProfile{}).IsEnabled("S3.14") {
+		t.Errorf("IsEnabled() == false with an empty profile")
+	}
+}
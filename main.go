@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -13,14 +17,45 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
 
 	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
 	"github.com/padeshaies/s3-bucket-analysis-tool/types"
 )
 
-// var cfg aws.Config
-
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import-pricing" {
+		if err := importPricing(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "analyze-policy" {
+		if err := analyzePolicy(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var lifecycle *s3types.BucketLifecycleConfiguration
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		if len(os.Args) < 3 {
+			log.Fatal("usage: simulate path/to/lifecycle.json [options]")
+		}
+
+		var err error
+		lifecycle, err = helpers.LoadLifecycleFile(os.Args[2])
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	audit := len(os.Args) > 1 && os.Args[1] == "audit"
+	compliance := len(os.Args) > 1 && os.Args[1] == "compliance"
+
 	displaySettings, err := buildDisplaySettings()
 	if err != nil {
 		log.Fatal(err)
@@ -31,118 +66,933 @@ func main() {
 		log.Fatal(err)
 	}
 
+	scanSettings, err := buildScanSettings()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The compliance rules are evaluated on the findings of the audit
+	scanSettings.Audit = audit || compliance
+
+	// The simulation needs every object, not only the aggregates
+	scanSettings.RecordObjects = lifecycle != nil
+
+	// The recommendations need the ages per prefix, not only per storage type
+	scanSettings.Recommendations = displaySettings.Recommendations
+
+	// The duplicate sets do not fit in the rows of the tabular outputs
+	if scanSettings.FindDuplicates && (displaySettings.Output == "csv" || displaySettings.Output == "tsv") {
+		log.Fatal("--duplicates is only supported by the text and json outputs")
+	}
+
+	var rules []types.Rule
+	if compliance {
+		rules, err = loadRules()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	requests, err := loadRequests()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	startTime := time.Now()
+
 	ctx := context.Background()
 	cfg, err := config.LoadDefaultConfig(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	client := s3.NewFromConfig(cfg)
+	clientPool := types.NewSafeClientPool(cfg)
+	bucketList := scanBuckets(ctx, clientPool, filterSettings, scanSettings)
+
+	// Every mode prices the buckets with the usage of their whole region
+	types.ShareRegionUsage(*bucketList.Buckets)
+
+	if compliance {
+		checkCompliance(ctx, cfg, bucketList, rules, startTime, displaySettings, scanSettings)
+		if bucketList.HasErrors() && scanSettings.FailOnPartial {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if scanSettings.Audit {
+		auditBuckets(ctx, cfg, bucketList, startTime, displaySettings, scanSettings)
+		if bucketList.HasErrors() && scanSettings.FailOnPartial {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if lifecycle != nil {
+		simulateLifecycle(ctx, cfg, clientPool, bucketList, lifecycle, startTime, displaySettings, scanSettings)
+		if bucketList.HasErrors() && scanSettings.FailOnPartial {
+			os.Exit(1)
+		}
+		return
+	}
+
+	for _, bucket := range *bucketList.Buckets {
+		bucket.Requests = requests[bucket.Name]
+	}
+
+	var duplicates []types.DuplicateSet
+	if scanSettings.FindDuplicates {
+		duplicates, err = types.FindDuplicates(bucketList.Duplicates, displaySettings.Pricing)
+		if err != nil {
+			bucketList.AddError(fmt.Errorf("finding duplicates: %w", err))
+		}
+	}
+
+	switch displaySettings.Output {
+	case "json", "csv", "tsv":
+		scanErrors := bucketList.Errors
+		account, err := getAccountID(ctx, cfg)
+		if err != nil {
+			scanErrors = append(scanErrors, fmt.Errorf("getting account: %w", err))
+		}
+
+		report := types.NewScanReport(startTime, time.Now(), account, *bucketList.Buckets, scanErrors, displaySettings)
+		report.Duplicates = duplicates
+		if err := writeReport(report, displaySettings.Output); err != nil {
+			log.Fatal(err)
+		}
+
+		// Tabular outputs have no room for errors, keep them on stderr
+		if displaySettings.Output != "json" {
+			for _, err := range report.Errors {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			for _, bucket := range report.Buckets {
+				for _, err := range bucket.Errors {
+					fmt.Fprintf(os.Stderr, "%v: %v\n", bucket.Name, err)
+				}
+			}
+		}
+	default:
+		if displaySettings.GroupBy == "region" {
+			summaries := types.GroupByRegion(*bucketList.Buckets)
+			for _, summary := range summaries {
+				summary.Println(displaySettings)
+			}
+			types.PrintGrandTotal(summaries, displaySettings)
+		} else {
+			for _, bucket := range *bucketList.Buckets {
+				bucket.Println(displaySettings)
+			}
+		}
+
+		printStaleSummary(*bucketList.Buckets, scanSettings.StaleDays)
+
+		if displaySettings.Recommendations {
+			printRecommendations(*bucketList.Buckets, displaySettings.Pricing)
+		}
+
+		types.PrintDuplicates(duplicates, displaySettings)
+
+		printMissingAbortRules(*bucketList.Buckets)
+
+		if bucketList.HasErrors() {
+			printErrorSummary(bucketList)
+		}
+	}
+
+	if bucketList.HasErrors() && scanSettings.FailOnPartial {
+		os.Exit(1)
+	}
+}
+
+// scanBuckets lists the buckets matching the filters and analyzes their objects
+func scanBuckets(ctx context.Context, clientPool *types.SafeClientPool, filterSettings types.SearchFilters, scanSettings types.ScanSettings) *types.SafeBucketList {
+	client := clientPool.GetClient(clientPool.Config.Region)
 	bucketList := &types.SafeBucketList{
 		Buckets: &[]*types.Bucket{},
 		Lock:    sync.Mutex{},
 	}
+	if scanSettings.FindDuplicates {
+		bucketList.Duplicates = types.NewDuplicateIndex()
+	}
 
 	bucketPaginator := s3.NewListBucketsPaginator(client, &s3.ListBucketsInput{
 		Prefix: aws.String(filterSettings.BucketName),
 	})
 
-	var tasks sync.WaitGroup
+	// Buckets from every page are fed to a bounded number of workers so they are analyzed in parallel
+	awsBuckets := make(chan s3types.Bucket, scanSettings.BucketConcurrency)
+
+	var workers sync.WaitGroup
+	for range scanSettings.BucketConcurrency {
+		workers.Add(1)
+		go analyzeBuckets(awsBuckets, clientPool, ctx, bucketList, &workers, filterSettings, scanSettings)
+	}
+
 	for bucketPaginator.HasMorePages() {
 		output, err := bucketPaginator.NextPage(ctx)
 		if err != nil {
-			log.Fatal(err)
+			bucketList.AddError(fmt.Errorf("listing buckets: %w", err))
+			break
 		}
 
-		tasks.Add(1)
-		go analyzeBucketPage(output, client, ctx, bucketList, &tasks, filterSettings)
+		for _, awsBucket := range output.Buckets {
+			awsBuckets <- awsBucket
+		}
 	}
 
-	tasks.Wait()
+	close(awsBuckets)
+	workers.Wait()
 
-	for _, bucket := range *bucketList.Buckets {
-		bucket.Println(displaySettings)
+	// Buckets complete in any order, keep the report stable
+	slices.SortFunc(*bucketList.Buckets, func(a, b *types.Bucket) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return bucketList
+}
+
+// reportWriter is implemented by the reports of every mode
+type reportWriter interface {
+	WriteJSON(w io.Writer) error
+	WriteCSV(w io.Writer, delimiter rune) error
+}
+
+// writeReport writes a report to stdout in the json, csv or tsv output
+func writeReport(report reportWriter, output string) error {
+	switch output {
+	case "csv":
+		return report.WriteCSV(os.Stdout, ',')
+	case "tsv":
+		return report.WriteCSV(os.Stdout, '\t')
+	default:
+		return report.WriteJSON(os.Stdout)
+	}
+}
+
+func getAccountID(ctx context.Context, cfg aws.Config) (string, error) {
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(identity.Account), nil
+}
+
+func printStaleSummary(buckets []*types.Bucket, staleDays int) {
+	stale := []*types.Bucket{}
+	for _, bucket := range buckets {
+		if bucket.IsStale() {
+			stale = append(stale, bucket)
+		}
+	}
+
+	if len(stale) == 0 {
+		return
+	}
+
+	fmt.Printf("Stale buckets, most STANDARD bytes not modified in over %v days:\n", staleDays)
+	for _, bucket := range stale {
+		fmt.Printf("  - %v: %.0f%% of %v\n", bucket.Name, bucket.StaleRatio()*100, helpers.FormatFileSize(bucket.ObjectsSize["STANDARD"], helpers.GB))
+	}
+}
+
+func printRecommendations(buckets []*types.Bucket, pricing helpers.Pricing) {
+	recommendations := types.AccountRecommendations(buckets, pricing)
+	if len(recommendations) == 0 {
+		return
+	}
+
+	totalSavings := 0.0
+	for _, recommendation := range recommendations {
+		totalSavings += recommendation.MonthlySavings
+	}
+
+	fmt.Printf("Recommendations, up to $%.2f per month of savings:\n", totalSavings)
+	for _, recommendation := range recommendations {
+		fmt.Printf("  - %v: %v\n", recommendation.Bucket, recommendation.Description)
 	}
 }
 
-func analyzeBucketPage(page *s3.ListBucketsOutput, client *s3.Client, ctx context.Context, bucketList *types.SafeBucketList, tasks *sync.WaitGroup, filterSettings types.SearchFilters) {
-	for _, awsBucket := range page.Buckets {
-		bucket := types.Bucket{
-			Name:                   *awsBucket.Name,
-			Region:                 *awsBucket.BucketRegion,
-			CreationDate:           *awsBucket.CreationDate,
-			ObjectsNumber:          map[string]int{},
-			ObjectsSize:            map[string]int{},
-			MostRecentModifiedDate: time.Time{},
-			Lock:                   sync.Mutex{},
+func printMissingAbortRules(buckets []*types.Bucket) {
+	missing := []*types.Bucket{}
+	for _, bucket := range buckets {
+		if bucket.MissesAbortIncompleteMultipartUploadRule() {
+			missing = append(missing, bucket)
 		}
+	}
+
+	if len(missing) == 0 {
+		return
+	}
+
+	fmt.Println("Buckets without a lifecycle rule aborting incomplete multipart uploads:")
+	for _, bucket := range missing {
+		fmt.Printf("  - %v: %v incomplete uploads (%v)\n", bucket.Name, len(bucket.MultipartUploads), helpers.FormatFileSize(bucket.MultipartUploadsSize(), helpers.GB))
+	}
+}
+
+func printErrorSummary(bucketList *types.SafeBucketList) {
+	fmt.Println("Scan completed with errors, results are partial:")
+	for _, err := range bucketList.Errors {
+		fmt.Printf("  - %v\n", err)
+	}
+	for _, bucket := range bucketList.FailedBuckets() {
+		fmt.Printf("  - %v: %v error(s)\n", bucket.Name, len(bucket.Errors))
+	}
+}
 
-		input := &s3.ListObjectsV2Input{
+func analyzeBuckets(awsBuckets <-chan s3types.Bucket, clientPool *types.SafeClientPool, ctx context.Context, bucketList *types.SafeBucketList, workers *sync.WaitGroup, filterSettings types.SearchFilters, scanSettings types.ScanSettings) {
+	for awsBucket := range awsBuckets {
+		analyzeBucket(awsBucket, clientPool, ctx, bucketList, filterSettings, scanSettings)
+	}
+
+	workers.Done()
+}
+
+func analyzeBucket(awsBucket s3types.Bucket, clientPool *types.SafeClientPool, ctx context.Context, bucketList *types.SafeBucketList, filterSettings types.SearchFilters, scanSettings types.ScanSettings) {
+	bucket := types.Bucket{
+		Name:         *awsBucket.Name,
+		Region:       aws.ToString(awsBucket.BucketRegion),
+		CreationDate: *awsBucket.CreationDate,
+		ObjectStats:  types.NewObjectStats(scanSettings),
+		Lock:         sync.Mutex{},
+	}
+
+	// Older ListBuckets responses may not include the region, assume the default one
+	if bucket.Region == "" {
+		bucket.Region = clientPool.Config.Region
+	}
+
+	// Buckets outside the default region must be listed with a client for their own region
+	client := clientPool.GetClient(bucket.Region)
+
+	if scanSettings.Audit {
+		analyzeBucketConfiguration(ctx, client, &bucket)
+		bucketList.AddBucket(&bucket)
+		return
+	}
+
+	if scanSettings.ListVersions {
+		versionPaginator := s3.NewListObjectVersionsPaginator(client, &s3.ListObjectVersionsInput{
+			Bucket: aws.String(bucket.Name),
+		})
+		err := analyzeBucketPages(ctx, &bucket, versionPaginator, scanSettings, func(page *s3.ListObjectVersionsOutput, stats *types.ObjectStats) {
+			analyzeBucketVersionPage(page, &bucket, stats, bucketList.Duplicates, filterSettings, scanSettings)
+		})
+		if err != nil {
+			bucket.AddError(fmt.Errorf("listing object versions: %w", err))
+		}
+	} else {
+		objectPaginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
 			Bucket: aws.String(bucket.Name),
+		})
+		err := analyzeBucketPages(ctx, &bucket, objectPaginator, scanSettings, func(page *s3.ListObjectsV2Output, stats *types.ObjectStats) {
+			analyzeBucketObjectPage(page, &bucket, stats, bucketList.Duplicates, filterSettings, scanSettings)
+		})
+		if err != nil {
+			bucket.AddError(fmt.Errorf("listing objects: %w", err))
 		}
+	}
 
-		// Adjust the client to the bucket region if necessary
-		// TODO - Fix this
-		/* var regionClient *s3.Client
-		if client.Options().Region != bucket.Region {
-			newCfg := cfg.Copy()
-			newCfg.Region = bucket.Region
-			regionClient = s3.NewFromConfig(newCfg)
-		} else {
-			regionClient = client
+	if scanSettings.ListMultipartUploads {
+		analyzeMultipartUploads(ctx, client, &bucket)
+	}
+
+	// Failed buckets are always kept so they show up in the report
+	if filterSettings.StorageType == "" || bucket.TotalObjectNumber() > 0 || bucket.TotalNoncurrentObjectNumber() > 0 || bucket.HasErrors() {
+		bucketList.AddBucket(&bucket)
+	}
+}
+
+// analyzeMultipartUploads sizes the incomplete multipart uploads of a bucket with their parts, and
+// fetches its lifecycle rules to tell if they are cleaned up. The uploads are still listed when the
+// lifecycle rules cannot be fetched.
+func analyzeMultipartUploads(ctx context.Context, client *s3.Client, bucket *types.Bucket) {
+	lifecycle, err := client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket.Name),
+	})
+	var apiErr smithy.APIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchLifecycleConfiguration") {
+		bucket.AddError(fmt.Errorf("getting lifecycle configuration: %w", err))
+		bucket.LifecycleUnknown = true
+	} else if err == nil {
+		bucket.LifecycleRules = lifecycle.Rules
+	}
+
+	uploadPaginator := s3.NewListMultipartUploadsPaginator(client, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(bucket.Name),
+	})
+	for uploadPaginator.HasMorePages() {
+		output, err := uploadPaginator.NextPage(ctx)
+		if err != nil {
+			bucket.AddError(fmt.Errorf("listing multipart uploads: %w", err))
+			return
 		}
 
-		fmt.Println("Searching region " + regionClient.Options().Region + " for bucket " + bucket.Name) */
+		for _, upload := range output.Uploads {
+			multipartUpload := types.MultipartUpload{
+				Key:         aws.ToString(upload.Key),
+				UploadID:    aws.ToString(upload.UploadId),
+				StorageType: string(upload.StorageClass),
+				Initiated:   aws.ToTime(upload.Initiated),
+			}
+			if multipartUpload.StorageType == "" {
+				multipartUpload.StorageType = "STANDARD"
+			}
 
-		objectPaginator := s3.NewListObjectsV2Paginator(client, input)
+			partPaginator := s3.NewListPartsPaginator(client, &s3.ListPartsInput{
+				Bucket:   aws.String(bucket.Name),
+				Key:      upload.Key,
+				UploadId: upload.UploadId,
+			})
+			for partPaginator.HasMorePages() {
+				parts, err := partPaginator.NextPage(ctx)
+				if err != nil {
+					// The upload may have been completed or aborted since it was listed
+					bucket.AddError(fmt.Errorf("listing parts of %v: %w", multipartUpload.Key, err))
+					break
+				}
 
-		var tasks sync.WaitGroup
-		for objectPaginator.HasMorePages() {
-			output, err := objectPaginator.NextPage(ctx)
-			if err != nil {
-				log.Fatal(err)
+				for _, part := range parts.Parts {
+					multipartUpload.Parts++
+					multipartUpload.Size += int(aws.ToInt64(part.Size))
+				}
 			}
 
-			tasks.Add(1)
-			go analyzeBucketObjectPage(output, &bucket, &tasks, filterSettings)
+			bucket.AddMultipartUpload(multipartUpload)
+		}
+	}
+
+	bucket.MultipartUploadsScanned = true
+}
+
+// analyzeBucketConfiguration fetches the security related settings of a bucket. The settings that
+// are not configured are left empty, the ones that cannot be fetched skip their checks.
+func analyzeBucketConfiguration(ctx context.Context, client *s3.Client, bucket *types.Bucket) {
+	configuration := &types.BucketConfiguration{}
+	bucket.Configuration = configuration
+
+	// fetched tells if a setting was fetched, notFound being the error code of a missing configuration
+	fetched := func(err error, setting, notFound string, checks ...string) bool {
+		if err == nil {
+			return true
+		}
+		var apiErr smithy.APIError
+		if notFound != "" && errors.As(err, &apiErr) && apiErr.ErrorCode() == notFound {
+			return false
+		}
+		bucket.AddError(fmt.Errorf("getting %v: %w", setting, err))
+		configuration.Missing = append(configuration.Missing, checks...)
+		return false
+	}
+	name := aws.String(bucket.Name)
+
+	publicAccessBlock, err := client.GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{Bucket: name})
+	if fetched(err, "public access block", "NoSuchPublicAccessBlockConfiguration", "public-access-block") {
+		configuration.PublicAccessBlock = publicAccessBlock.PublicAccessBlockConfiguration
+	}
+
+	policy, err := client.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: name})
+	if fetched(err, "bucket policy", "NoSuchBucketPolicy", "policy") {
+		configuration.Policy = policy.Policy
+
+		policyStatus, err := client.GetBucketPolicyStatus(ctx, &s3.GetBucketPolicyStatusInput{Bucket: name})
+		if fetched(err, "bucket policy status", "", "policy-status") {
+			configuration.PolicyStatus = policyStatus.PolicyStatus
 		}
+	}
+
+	acl, err := client.GetBucketAcl(ctx, &s3.GetBucketAclInput{Bucket: name})
+	if fetched(err, "bucket ACL", "", "acl") {
+		configuration.Grants = acl.Grants
+		configuration.Owner = acl.Owner
+	}
 
-		tasks.Wait()
+	ownership, err := client.GetBucketOwnershipControls(ctx, &s3.GetBucketOwnershipControlsInput{Bucket: name})
+	if fetched(err, "ownership controls", "OwnershipControlsNotFoundError", "ownership") && ownership.OwnershipControls != nil && len(ownership.OwnershipControls.Rules) > 0 {
+		configuration.ObjectOwnership = ownership.OwnershipControls.Rules[0].ObjectOwnership
+	}
+
+	encryption, err := client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{Bucket: name})
+	if fetched(err, "default encryption", "ServerSideEncryptionConfigurationNotFoundError", "encryption") {
+		configuration.Encryption = encryption.ServerSideEncryptionConfiguration
+	}
+
+	versioning, err := client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: name})
+	if fetched(err, "versioning", "", "versioning", "mfa-delete", "versioning-lifecycle") {
+		configuration.Versioning = versioning.Status
+		configuration.MFADelete = versioning.MFADelete
+	}
+
+	objectLock, err := client.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{Bucket: name})
+	if fetched(err, "object lock configuration", "ObjectLockConfigurationNotFoundError", "object-lock") {
+		configuration.ObjectLock = objectLock.ObjectLockConfiguration
+	}
 
-		if filterSettings.StorageType == "" || (filterSettings.StorageType != "" && bucket.TotalObjectNumber() > 0) {
-			bucketList.Lock.Lock()
-			*bucketList.Buckets = append(*bucketList.Buckets, &bucket)
-			bucketList.Lock.Unlock()
+	logging, err := client.GetBucketLogging(ctx, &s3.GetBucketLoggingInput{Bucket: name})
+	if fetched(err, "server access logging", "", "logging") {
+		configuration.Logging = logging.LoggingEnabled
+	}
+
+	lifecycle, err := client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: name})
+	if fetched(err, "lifecycle configuration", "NoSuchLifecycleConfiguration", "lifecycle", "versioning-lifecycle") {
+		bucket.LifecycleRules = lifecycle.Rules
+		configuration.HasLifecycle = true
+	}
+}
+
+// paginator is implemented by the SDK paginators
+type paginator[T any] interface {
+	HasMorePages() bool
+	NextPage(ctx context.Context, optFns ...func(*s3.Options)) (T, error)
+}
+
+// analyzeBucketPages feeds the pages to a bounded number of workers, each one keeping its own
+// partial stats that are merged into the bucket once all pages have been consumed. The pages
+// read before an error are still analyzed.
+func analyzeBucketPages[T any](ctx context.Context, bucket *types.Bucket, pager paginator[T], scanSettings types.ScanSettings, analyzePage func(T, *types.ObjectStats)) error {
+	pages := make(chan T, scanSettings.PageConcurrency)
+
+	var workers sync.WaitGroup
+	for range scanSettings.PageConcurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+
+			stats := types.NewObjectStats(scanSettings)
+			for page := range pages {
+				analyzePage(page, stats)
+			}
+			bucket.MergeStats(stats)
+		}()
+	}
+
+	var err error
+	for pager.HasMorePages() {
+		var output T
+		output, err = pager.NextPage(ctx)
+		if err != nil {
+			break
 		}
+
+		pages <- output
 	}
 
-	tasks.Done()
+	close(pages)
+	workers.Wait()
+	return err
 }
 
-func analyzeBucketObjectPage(page *s3.ListObjectsV2Output, bucket *types.Bucket, tasks *sync.WaitGroup, filterSettings types.SearchFilters) {
+// analyzeBucketObjectPage adds the objects of a page to the stats of a worker, and to the duplicate
+// index when set
+func analyzeBucketObjectPage(page *s3.ListObjectsV2Output, bucket *types.Bucket, stats *types.ObjectStats, duplicates *types.DuplicateIndex, filterSettings types.SearchFilters, scanSettings types.ScanSettings) {
 	for _, object := range page.Contents {
-		bucket.Lock.Lock()
-
 		// Apply storage type filter
 		if filterSettings.StorageType != "" && string(object.StorageClass) != filterSettings.StorageType {
-			bucket.Lock.Unlock()
 			continue
 		}
 
-		bucket.ObjectsNumber[string(object.StorageClass)]++
-		bucket.ObjectsSize[string(object.StorageClass)] += int(*object.Size)
-		if object.LastModified.After(bucket.MostRecentModifiedDate) {
-			bucket.MostRecentModifiedDate = *object.LastModified
+		record := types.ObjectRecord{
+			Key:          aws.ToString(object.Key),
+			StorageType:  string(object.StorageClass),
+			Size:         int(aws.ToInt64(object.Size)),
+			LastModified: aws.ToTime(object.LastModified),
+			ETag:         strings.Trim(aws.ToString(object.ETag), `"`),
+		}
+
+		stats.AddObject(record)
+		if scanSettings.RecordObjects {
+			stats.RecordObject(record)
+		}
+		if duplicates != nil {
+			duplicates.Add(bucket, record)
+		}
+	}
+}
+
+// analyzeBucketVersionPage adds the current versions like analyzeBucketObjectPage, and counts the
+// noncurrent versions and delete markers apart
+func analyzeBucketVersionPage(page *s3.ListObjectVersionsOutput, bucket *types.Bucket, stats *types.ObjectStats, duplicates *types.DuplicateIndex, filterSettings types.SearchFilters, scanSettings types.ScanSettings) {
+	for _, version := range page.Versions {
+		// Apply storage type filter
+		if filterSettings.StorageType != "" && string(version.StorageClass) != filterSettings.StorageType {
+			continue
+		}
+
+		record := types.ObjectRecord{
+			Key:          aws.ToString(version.Key),
+			StorageType:  string(version.StorageClass),
+			Size:         int(aws.ToInt64(version.Size)),
+			LastModified: aws.ToTime(version.LastModified),
+			ETag:         strings.Trim(aws.ToString(version.ETag), `"`),
+		}
+
+		if !aws.ToBool(version.IsLatest) {
+			stats.AddNoncurrentVersion(record)
+			continue
+		}
+
+		stats.AddObject(record)
+		if scanSettings.RecordObjects {
+			stats.RecordObject(record)
+		}
+		if duplicates != nil {
+			duplicates.Add(bucket, record)
+		}
+	}
+
+	stats.DeleteMarkers += len(page.DeleteMarkers)
+}
+
+// simulateLifecycle reports the effect of a lifecycle configuration on every scanned bucket
+func simulateLifecycle(ctx context.Context, cfg aws.Config, clientPool *types.SafeClientPool, bucketList *types.SafeBucketList, lifecycle *s3types.BucketLifecycleConfiguration, startTime time.Time, displaySettings types.DisplaySettings, scanSettings types.ScanSettings) {
+	// Tags are fetched for a bounded number of buckets in parallel, like the scan
+	buckets := make(chan *types.Bucket, scanSettings.BucketConcurrency)
+
+	var workers sync.WaitGroup
+	for range scanSettings.BucketConcurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for bucket := range buckets {
+				loadObjectTags(ctx, clientPool, bucket, lifecycle, scanSettings)
+			}
+		}()
+	}
+
+	for _, bucket := range *bucketList.Buckets {
+		buckets <- bucket
+	}
+	close(buckets)
+	workers.Wait()
+
+	now := time.Now()
+	simulations := []types.LifecycleSimulation{}
+	for _, bucket := range *bucketList.Buckets {
+		simulations = append(simulations, types.SimulateLifecycle(bucket, lifecycle, now, displaySettings.Pricing))
+	}
+
+	switch displaySettings.Output {
+	case "json", "csv", "tsv":
+		scanErrors := bucketList.Errors
+		account, err := getAccountID(ctx, cfg)
+		if err != nil {
+			scanErrors = append(scanErrors, fmt.Errorf("getting account: %w", err))
+		}
+
+		report := types.NewSimulationReport(startTime, time.Now(), account, simulations, scanErrors, displaySettings)
+		if err := writeReport(report, displaySettings.Output); err != nil {
+			log.Fatal(err)
+		}
+
+		// Tabular outputs have no room for errors, keep them on stderr
+		if displaySettings.Output != "json" {
+			for _, err := range report.Errors {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			for _, simulation := range report.Buckets {
+				for _, err := range simulation.Errors {
+					fmt.Fprintf(os.Stderr, "%v: %v\n", simulation.Bucket, err)
+				}
+			}
+		}
+	default:
+		for _, simulation := range simulations {
+			simulation.Println(displaySettings)
+		}
+		types.PrintSimulationTotals(simulations)
+
+		if bucketList.HasErrors() {
+			printErrorSummary(bucketList)
+		}
+	}
+}
+
+// auditBuckets reports the configuration findings of every scanned bucket
+func auditBuckets(ctx context.Context, cfg aws.Config, bucketList *types.SafeBucketList, startTime time.Time, displaySettings types.DisplaySettings, scanSettings types.ScanSettings) {
+	account, trustedAccounts := trustScannedAccount(ctx, cfg, bucketList, scanSettings.TrustedAccounts)
+
+	audits := []types.BucketAudit{}
+	for _, bucket := range *bucketList.Buckets {
+		audits = append(audits, bucket.Audit(trustedAccounts))
+	}
+
+	switch displaySettings.Output {
+	case "json", "csv", "tsv":
+		report := types.NewAuditReport(startTime, time.Now(), account, audits, bucketList.Errors, displaySettings)
+		if err := writeReport(report, displaySettings.Output); err != nil {
+			log.Fatal(err)
+		}
+
+		// Tabular outputs have no room for errors, keep them on stderr
+		if displaySettings.Output != "json" {
+			for _, err := range report.Errors {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			for _, audit := range report.Buckets {
+				for _, err := range audit.Errors {
+					fmt.Fprintf(os.Stderr, "%v: %v\n", audit.Bucket, err)
+				}
+			}
+		}
+	default:
+		for _, audit := range audits {
+			audit.Println()
+		}
+		types.PrintAuditSummary(audits)
+
+		if bucketList.HasErrors() {
+			printErrorSummary(bucketList)
+		}
+	}
+}
+
+// checkCompliance reports the violations of the enabled rules by every scanned bucket
+func checkCompliance(ctx context.Context, cfg aws.Config, bucketList *types.SafeBucketList, rules []types.Rule, startTime time.Time, displaySettings types.DisplaySettings, scanSettings types.ScanSettings) {
+	account, trustedAccounts := trustScannedAccount(ctx, cfg, bucketList, scanSettings.TrustedAccounts)
+	results := types.Evaluate(rules, *bucketList.Buckets, trustedAccounts)
+
+	switch displaySettings.Output {
+	case "json", "csv", "tsv":
+		report := types.NewComplianceReport(startTime, time.Now(), account, results, bucketList.Errors, displaySettings)
+		if err := writeReport(report, displaySettings.Output); err != nil {
+			log.Fatal(err)
+		}
+
+		// Tabular outputs have no room for errors, keep them on stderr
+		if displaySettings.Output != "json" {
+			for _, err := range report.Errors {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			for _, bucket := range *bucketList.Buckets {
+				for _, err := range bucket.Errors {
+					fmt.Fprintf(os.Stderr, "%v: %v\n", bucket.Name, err)
+				}
+			}
+		}
+	default:
+		types.PrintRuleResults(results, len(*bucketList.Buckets))
+
+		if bucketList.HasErrors() {
+			printErrorSummary(bucketList)
+		}
+	}
+}
+
+// trustScannedAccount returns the ID of the scanned account and the trusted accounts including it,
+// the bucket policies may grant access to the scanned account without being reported
+func trustScannedAccount(ctx context.Context, cfg aws.Config, bucketList *types.SafeBucketList, trustedAccounts []string) (string, []string) {
+	account, err := getAccountID(ctx, cfg)
+	if err != nil {
+		bucketList.AddError(fmt.Errorf("getting account: %w", err))
+		return account, trustedAccounts
+	}
+
+	return account, append(slices.Clone(trustedAccounts), account)
+}
+
+// analyzePolicy checks a bucket policy document without calling AWS
+func analyzePolicy(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: analyze-policy path/to/policy.json [--trusted-accounts 111122223333,...] [--output text|json|csv|tsv]")
+	}
+
+	policy, err := helpers.LoadPolicyFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	displaySettings, err := buildDisplaySettings()
+	if err != nil {
+		return err
+	}
+	scanSettings, err := buildScanSettings()
+	if err != nil {
+		return err
+	}
+
+	audit := types.BucketAudit{
+		Bucket:   args[0],
+		Findings: types.AnalyzePolicy(policy, scanSettings.TrustedAccounts),
+		Errors:   []string{},
+	}
+
+	switch displaySettings.Output {
+	case "json", "csv", "tsv":
+		now := time.Now()
+		report := types.NewAuditReport(now, now, "", []types.BucketAudit{audit}, nil, displaySettings)
+		return writeReport(report, displaySettings.Output)
+	default:
+		audit.Println()
+	}
+	return nil
+}
+
+// loadObjectTags fetches the tags of the objects that can only be matched by a rule using them, it
+// costs one GetObjectTagging request per object. Objects whose tags cannot be fetched are simulated
+// without tags and the error is recorded on the bucket.
+func loadObjectTags(ctx context.Context, clientPool *types.SafeClientPool, bucket *types.Bucket, lifecycle *s3types.BucketLifecycleConfiguration, scanSettings types.ScanSettings) {
+	tagRules := []s3types.LifecycleRule{}
+	for _, rule := range lifecycle.Rules {
+		if rule.Status == s3types.ExpirationStatusEnabled && helpers.LifecycleRuleHasTagFilter(rule) {
+			tagRules = append(tagRules, rule)
+		}
+	}
+	if len(tagRules) == 0 {
+		return
+	}
+
+	client := clientPool.GetClient(bucket.Region)
+
+	// Objects are fed by index to a bounded number of workers, each one only writing the tags of its own objects
+	indexes := make(chan int, scanSettings.PageConcurrency)
+
+	// The failures are reported as a single error of the bucket with the first cause
+	var failuresLock sync.Mutex
+	failures := 0
+	var firstFailure error
+
+	var workers sync.WaitGroup
+	for range scanSettings.PageConcurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+
+			for i := range indexes {
+				object := bucket.Objects[i]
+				output, err := client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+					Bucket: aws.String(bucket.Name),
+					Key:    aws.String(object.Key),
+				})
+				if err != nil {
+					failuresLock.Lock()
+					if failures == 0 {
+						firstFailure = fmt.Errorf("%v: %w", object.Key, err)
+					}
+					failures++
+					failuresLock.Unlock()
+					continue
+				}
+
+				tags := map[string]string{}
+				for _, tag := range output.TagSet {
+					tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+				}
+				bucket.Objects[i].Tags = tags
+			}
+		}()
+	}
+
+	for i, object := range bucket.Objects {
+		// Ignoring the tags tells if the rest of the filter (prefix, size) selects the object
+		for _, rule := range tagRules {
+			if helpers.LifecycleRuleMatches(withoutTagFilter(rule), object.Key, object.Size, nil) {
+				indexes <- i
+				break
+			}
+		}
+	}
+
+	close(indexes)
+	workers.Wait()
+
+	if failures > 0 {
+		bucket.AddError(fmt.Errorf("getting tags of %v objects, first error: %w", failures, firstFailure))
+	}
+}
+
+func withoutTagFilter(rule s3types.LifecycleRule) s3types.LifecycleRule {
+	filter := *rule.Filter
+	filter.Tag = nil
+	if filter.And != nil {
+		and := *filter.And
+		and.Tags = nil
+		filter.And = &and
+	}
+	rule.Filter = &filter
+	return rule
+}
+
+// importPricing converts an AmazonS3 Price List offer file into a pricing catalog
+func importPricing(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: import-pricing path/to/index.json path/to/pricing.json")
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	catalog, err := helpers.ImportPriceListOffer(data)
+	if err != nil {
+		return err
+	}
+
+	output, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(args[1], append(output, '\n'), 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported pricing version %v for %v regions into %v\n", catalog.Version, len(catalog.RegionGroups), args[1])
+	return nil
+}
+
+// loadRules returns the compliance rules enabled by the --profile file, every rule when none is provided
+func loadRules() ([]types.Rule, error) {
+	flags := os.Args[1:]
+	profile := helpers.RuleProfile{}
+
+	if index := slices.Index(flags, "--profile"); index != -1 {
+		if len(flags) < index+2 {
+			return nil, fmt.Errorf("please provide a rule profile file")
+		}
+
+		var err error
+		profile, err = helpers.LoadRuleProfile(flags[index+1])
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		if !slices.Contains(bucket.StorageTypes, string(object.StorageClass)) {
-			bucket.StorageTypes = append(bucket.StorageTypes, string(object.StorageClass))
+	return types.Rules(profile)
+}
+
+// loadRequests reads the monthly requests from --requests-file and --access-logs, both can be combined
+func loadRequests() (helpers.BucketRequests, error) {
+	requests := helpers.BucketRequests{}
+	flags := os.Args[1:]
+
+	if index := slices.Index(flags, "--requests-file"); index != -1 {
+		if len(flags) < index+2 {
+			return requests, fmt.Errorf("please provide a requests file")
 		}
 
-		bucket.Lock.Unlock()
+		fileRequests, err := helpers.LoadRequestsFile(flags[index+1])
+		if err != nil {
+			return requests, err
+		}
+		requests.Merge(fileRequests)
+	}
+
+	if index := slices.Index(flags, "--access-logs"); index != -1 {
+		if len(flags) < index+2 {
+			return requests, fmt.Errorf("please provide an access logs file or directory")
+		}
+
+		logRequests, err := helpers.ParseAccessLogs(flags[index+1])
+		if err != nil {
+			return requests, err
+		}
+		requests.Merge(logRequests)
 	}
 
-	tasks.Done()
+	return requests, nil
 }
 
 func buildDisplaySettings() (types.DisplaySettings, error) {
@@ -150,6 +1000,7 @@ func buildDisplaySettings() (types.DisplaySettings, error) {
 		FileSize: helpers.B,
 		GroupBy:  "",
 		Timezone: time.Local,
+		Output:   "text",
 	}
 
 	flags := os.Args[1:]
@@ -186,6 +1037,172 @@ func buildDisplaySettings() (types.DisplaySettings, error) {
 		result.Timezone = loc
 	}
 
+	if slices.Contains(flags, "--recommend") {
+		result.Recommendations = true
+	}
+
+	if index := slices.Index(flags, "--output"); index != -1 {
+		if len(flags) < index+2 {
+			return result, fmt.Errorf("please provide an output format")
+		}
+
+		output := flags[index+1]
+		if output != "text" && output != "json" && output != "csv" && output != "tsv" {
+			return result, fmt.Errorf("invalid output format. please use 'text', 'json', 'csv' or 'tsv'")
+		}
+		result.Output = output
+	}
+
+	if index := slices.Index(flags, "--pricing-file"); index != -1 {
+		if len(flags) < index+2 {
+			return result, fmt.Errorf("please provide a pricing file")
+		}
+
+		catalog, err := helpers.LoadPricingFile(flags[index+1])
+		if err != nil {
+			return result, err
+		}
+		result.Pricing.Catalog = catalog
+	}
+
+	if index := slices.Index(flags, "--intelligent-tiering"); index != -1 {
+		if len(flags) < index+2 {
+			return result, fmt.Errorf("please provide an intelligent tiering split")
+		}
+
+		split, err := helpers.ParseIntelligentTieringSplit(flags[index+1])
+		if err != nil {
+			return result, err
+		}
+		result.Pricing.IntelligentTieringSplit = split
+	}
+
+	return result, nil
+}
+
+func buildScanSettings() (types.ScanSettings, error) {
+	result := types.ScanSettings{
+		FailOnPartial:     false,
+		BucketConcurrency: 4,
+		PageConcurrency:   8,
+		StaleDays:         helpers.DefaultStaleDays,
+		PrefixDelimiter:   "/",
+		TrustedAccounts:   []string{},
+	}
+
+	flags := os.Args[1:]
+
+	if slices.Contains(flags, "--fail-on-partial") {
+		result.FailOnPartial = true
+	}
+
+	if slices.Contains(flags, "--versions") {
+		result.ListVersions = true
+	}
+
+	if slices.Contains(flags, "--multipart-uploads") {
+		result.ListMultipartUploads = true
+	}
+
+	if slices.Contains(flags, "--duplicates") {
+		result.FindDuplicates = true
+	}
+
+	if index := slices.Index(flags, "--stale-days"); index != -1 {
+		if len(flags) < index+2 {
+			return result, fmt.Errorf("please provide a number of days")
+		}
+
+		days, err := strconv.Atoi(flags[index+1])
+		if err != nil || days < 1 {
+			return result, fmt.Errorf("invalid number of days. please use a number greater than 0")
+		}
+		result.StaleDays = days
+	}
+
+	if index := slices.Index(flags, "--top"); index != -1 {
+		if len(flags) < index+2 {
+			return result, fmt.Errorf("please provide a number of objects")
+		}
+
+		n, err := strconv.Atoi(flags[index+1])
+		if err != nil || n < 1 {
+			return result, fmt.Errorf("invalid number of objects. please use a number greater than 0")
+		}
+		result.TopObjects = n
+	}
+
+	if index := slices.Index(flags, "--prefix-depth"); index != -1 {
+		if len(flags) < index+2 {
+			return result, fmt.Errorf("please provide a prefix depth")
+		}
+
+		depth, err := strconv.Atoi(flags[index+1])
+		if err != nil || depth < 1 {
+			return result, fmt.Errorf("invalid prefix depth. please use a number greater than 0")
+		}
+		result.PrefixDepth = depth
+	}
+
+	if index := slices.Index(flags, "--prefix-delimiter"); index != -1 {
+		if len(flags) < index+2 || flags[index+1] == "" {
+			return result, fmt.Errorf("please provide a prefix delimiter")
+		}
+		result.PrefixDelimiter = flags[index+1]
+	}
+
+	if index := slices.Index(flags, "--trusted-accounts"); index != -1 {
+		if len(flags) < index+2 {
+			return result, fmt.Errorf("please provide a list of trusted accounts")
+		}
+
+		accounts := strings.Split(flags[index+1], ",")
+		for _, account := range accounts {
+			if len(account) != 12 || strings.Trim(account, "0123456789") != "" {
+				return result, fmt.Errorf("invalid trusted account %q. please use 12-digit account IDs separated by commas", account)
+			}
+		}
+		result.TrustedAccounts = accounts
+	}
+
+	if index := slices.Index(flags, "--concurrency"); index != -1 {
+		if len(flags) < index+2 {
+			return result, fmt.Errorf("please provide a concurrency option")
+		}
+
+		// A single number applies to both levels of parallelism
+		if limit, err := strconv.Atoi(flags[index+1]); err == nil {
+			if limit < 1 {
+				return result, fmt.Errorf("invalid concurrency limit. please use a number greater than 0")
+			}
+			result.BucketConcurrency = limit
+			result.PageConcurrency = limit
+			return result, nil
+		}
+
+		for _, option := range strings.Split(flags[index+1], ";") {
+			keyValue := strings.Split(option, ":")
+
+			if len(keyValue) != 2 {
+				return result, fmt.Errorf("invalid concurrency option. please use a key and a value separated by a colon")
+			}
+
+			limit, err := strconv.Atoi(keyValue[1])
+			if err != nil || limit < 1 {
+				return result, fmt.Errorf("invalid concurrency limit. please use a number greater than 0")
+			}
+
+			switch keyValue[0] {
+			case "buckets":
+				result.BucketConcurrency = limit
+			case "pages":
+				result.PageConcurrency = limit
+			default:
+				return result, fmt.Errorf("invalid concurrency option. please use 'buckets' or 'pages'")
+			}
+		}
+	}
+
 	return result, nil
 }
 
@@ -0,0 +1,152 @@
+package types
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BucketAudit is the result of the configuration checks of a bucket
+type BucketAudit struct {
+	Bucket   string    `json:"bucket"`
+	Region   string    `json:"region"`
+	Findings []Finding `json:"findings"`
+	Errors   []string  `json:"errors"`
+}
+
+func (b *Bucket) Audit(trustedAccounts []string) BucketAudit {
+	return BucketAudit{
+		Bucket:   b.Name,
+		Region:   b.Region,
+		Findings: b.Findings(trustedAccounts),
+		Errors:   errorStrings(b.Errors),
+	}
+}
+
+// Count returns the number of findings with the given status
+func (a BucketAudit) Count(status string) int {
+	count := 0
+	for _, finding := range a.Findings {
+		if finding.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (a BucketAudit) Println() {
+	name := a.Bucket
+	// Policies analyzed offline have no region
+	if a.Region != "" {
+		name += fmt.Sprintf(" (%v)", a.Region)
+	}
+	fmt.Printf("Bucket: %v - %v fail, %v warn, %v pass\n", name, a.Count(FindingFail), a.Count(FindingWarn), a.Count(FindingPass))
+	for _, finding := range a.Findings {
+		check := finding.Check
+		if finding.Sid != "" {
+			check += fmt.Sprintf(" (statement %v)", finding.Sid)
+		}
+		fmt.Printf("  - [%v] %v: %v\n", strings.ToUpper(finding.Status), check, finding.Message)
+	}
+	for _, err := range a.Errors {
+		fmt.Printf("  - Error: %v\n", err)
+	}
+}
+
+// PrintAuditSummary counts the findings of every bucket, and the buckets failing or warning on each check
+func PrintAuditSummary(audits []BucketAudit) {
+	statuses := map[string]int{}
+	failing, warning := map[string]int{}, map[string]int{}
+	for _, audit := range audits {
+		// A check can have several findings, e.g. one per policy statement, the buckets are counted once
+		bucketFailing, bucketWarning := map[string]bool{}, map[string]bool{}
+		for _, finding := range audit.Findings {
+			statuses[finding.Status]++
+			switch finding.Status {
+			case FindingFail:
+				bucketFailing[finding.Check] = true
+			case FindingWarn:
+				bucketWarning[finding.Check] = true
+			}
+		}
+		for check := range bucketFailing {
+			failing[check]++
+		}
+		for check := range bucketWarning {
+			warning[check]++
+		}
+	}
+
+	fmt.Printf("Audit summary: %v buckets, %v fail, %v warn, %v pass\n", len(audits), statuses[FindingFail], statuses[FindingWarn], statuses[FindingPass])
+	printCheckCounts("Failing checks", failing)
+	printCheckCounts("Warning checks", warning)
+}
+
+// printCheckCounts lists the checks from the one affecting the most buckets to the least
+func printCheckCounts(title string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+
+	checks := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(checks, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), strings.Compare(a, b))
+	})
+
+	fmt.Printf("  - %v:\n", title)
+	for _, check := range checks {
+		fmt.Printf("    - %v: %v buckets\n", check, counts[check])
+	}
+}
+
+// AuditReport is the machine-readable view of an audit
+type AuditReport struct {
+	StartTime string        `json:"startTime"`
+	EndTime   string        `json:"endTime"`
+	Account   string        `json:"account"`
+	Buckets   []BucketAudit `json:"buckets"`
+	Errors    []string      `json:"errors"`
+}
+
+func NewAuditReport(startTime, endTime time.Time, account string, audits []BucketAudit, errors []error, displaySettings DisplaySettings) AuditReport {
+	return AuditReport{
+		StartTime: formatDate(startTime, displaySettings),
+		EndTime:   formatDate(endTime, displaySettings),
+		Account:   account,
+		Buckets:   audits,
+		Errors:    errorStrings(errors),
+	}
+}
+
+func (r AuditReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes one row per finding
+func (r AuditReport) WriteCSV(w io.Writer, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	if err := writer.Write([]string{"bucket", "region", "check", "status", "sid", "message"}); err != nil {
+		return err
+	}
+
+	for _, audit := range r.Buckets {
+		for _, finding := range audit.Findings {
+			if err := writer.Write([]string{audit.Bucket, audit.Region, finding.Check, finding.Status, finding.Sid, finding.Message}); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
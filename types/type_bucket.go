@@ -2,53 +2,308 @@ package types
 
 import (
 	"fmt"
+	"maps"
+	"math"
+	"slices"
 	"sync"
 	"time"
 
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
 	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
 )
 
 type Bucket struct {
-	Name                   string
-	Region                 string
-	StorageTypes           []string
-	CreationDate           time.Time
-	MostRecentModifiedDate time.Time
-	ObjectsNumber          map[string]int
-	ObjectsSize            map[string]int
+	Name         string
+	Region       string
+	CreationDate time.Time
+
+	*ObjectStats
+
+	// Bytes per storage type stored by every scanned bucket of the region, current and noncurrent
+	// versions and multipart upload parts, the tiered rates apply to these totals. When nil the
+	// bucket is priced on its own.
+	RegionObjectsSize map[string]int
+
+	// Monthly requests per storage type, the requests with an empty storage type are attributed
+	// to the storage type holding the most objects
+	Requests map[string]helpers.RequestCounts
+
+	// Incomplete multipart uploads and lifecycle rules, only set when MultipartUploadsScanned
+	MultipartUploadsScanned bool
+	MultipartUploads        []MultipartUpload
+	LifecycleRules          []s3types.LifecycleRule
+	// Set when the lifecycle configuration could not be fetched, the uploads are still listed but
+	// the abort rule is not checked
+	LifecycleUnknown bool
+
+	// Security related settings, only set by the audit
+	Configuration *BucketConfiguration
+
+	// Errors encountered while scanning the bucket, the other fields only hold partial results when set
+	Errors []error
 
 	Lock sync.Mutex
 }
 
-func (b *Bucket) TotalSize() int {
-	totalSize := 0
-	for _, size := range b.ObjectsSize {
-		totalSize += size
+func (b *Bucket) AddError(err error) {
+	b.Lock.Lock()
+	b.Errors = append(b.Errors, err)
+	b.Lock.Unlock()
+}
+
+// MergeStats adds the partial results of a worker to the bucket
+func (b *Bucket) MergeStats(stats *ObjectStats) {
+	b.Lock.Lock()
+	b.ObjectStats.Merge(stats)
+	b.Lock.Unlock()
+}
+
+func (b *Bucket) HasErrors() bool {
+	return len(b.Errors) > 0
+}
+
+// IntelligentTieringSplit returns the configured access tier split, or the one inferred from the
+// monitored objects of the bucket when none was configured
+func (b *Bucket) IntelligentTieringSplit(pricing helpers.Pricing) (split helpers.IntelligentTieringSplit, inferred bool) {
+	if configured := pricing.IntelligentTieringSplit; configured != nil {
+		return configured, false
+	}
+
+	split = helpers.IntelligentTieringSplit{}
+	totalSize := b.ObjectsSize["INTELLIGENT_TIERING"] - b.SmallObjectsSize["INTELLIGENT_TIERING"]
+	if totalSize == 0 {
+		split[helpers.FrequentAccess] = 1
+		return split, true
+	}
+
+	for tier, size := range b.IntelligentTieringSize {
+		split[tier] = float64(size) / float64(totalSize)
 	}
-	return totalSize
+	return split, true
 }
 
-func (b *Bucket) TotalObjectNumber() int {
-	totalObjectNumber := 0
-	for _, number := range b.ObjectsNumber {
-		totalObjectNumber += number
+// MonitoredObjectsNumber is the number of Intelligent-Tiering objects billed the monitoring fee
+func (b *Bucket) MonitoredObjectsNumber() int {
+	return b.ObjectsNumber["INTELLIGENT_TIERING"] - b.SmallObjectsNumber["INTELLIGENT_TIERING"]
+}
+
+// StorageCosts details the storage cost of each storage type, with the billable bytes, the
+// overhead of the minimum billable size and archive metadata, and the minimum storage duration left
+func (b *Bucket) StorageCosts(pricing helpers.Pricing) (map[string]helpers.StorageCost, error) {
+	costs := map[string]helpers.StorageCost{}
+	for _, storageType := range b.StorageTypes {
+		regionSize := b.ObjectsSize[storageType]
+		if b.RegionObjectsSize != nil {
+			regionSize = b.RegionObjectsSize[storageType]
+		}
+
+		if storageType == "INTELLIGENT_TIERING" {
+			split, _ := b.IntelligentTieringSplit(pricing)
+			cost, err := pricing.CalculateIntelligentTieringCost(b.Region, b.ObjectsSize[storageType], b.MonitoredObjectsNumber(), b.SmallObjectsSize[storageType], regionSize, split)
+			if err != nil {
+				return costs, err
+			}
+			costs[storageType] = helpers.StorageCost{
+				ActualBytes:   b.ObjectsSize[storageType],
+				BillableBytes: b.ObjectsSize[storageType],
+				Cost:          cost,
+			}
+			continue
+		}
+
+		cost, err := pricing.CalculateStorageCost(storageType, b.Region, b.ObjectsSize[storageType], b.ObjectsNumber[storageType], b.SmallObjectsNumber[storageType], b.SmallObjectsSize[storageType], regionSize)
+		if err != nil {
+			return costs, err
+		}
+		if cost.MinimumDurationCost, err = pricing.CalculateMinimumDurationCost(storageType, b.Region, b.MinimumDurationByteDays[storageType]); err != nil {
+			return costs, err
+		}
+		costs[storageType] = cost
 	}
-	return totalObjectNumber
+	return costs, nil
 }
 
-func (b *Bucket) TotalCost() (float64, error) {
+// NoncurrentCosts details the storage cost of the noncurrent versions of each storage type. They
+// count in the region usage along with the current versions.
+func (b *Bucket) NoncurrentCosts(pricing helpers.Pricing) (map[string]helpers.StorageCost, error) {
+	costs := map[string]helpers.StorageCost{}
+	for storageType, size := range b.NoncurrentObjectsSize {
+		regionSize := b.ObjectsSize[storageType] + size
+		if b.RegionObjectsSize != nil {
+			regionSize = b.RegionObjectsSize[storageType]
+		}
+
+		number, smallNumber := b.NoncurrentObjectsNumber[storageType], b.NoncurrentSmallObjectsNumber[storageType]
+		if storageType == "INTELLIGENT_TIERING" {
+			split, _ := b.IntelligentTieringSplit(pricing)
+			cost, err := pricing.CalculateIntelligentTieringCost(b.Region, size, number-smallNumber, b.NoncurrentSmallObjectsSize[storageType], regionSize, split)
+			if err != nil {
+				return costs, err
+			}
+			costs[storageType] = helpers.StorageCost{ActualBytes: size, BillableBytes: size, Cost: cost}
+			continue
+		}
+
+		cost, err := pricing.CalculateStorageCost(storageType, b.Region, size, number, smallNumber, b.NoncurrentSmallObjectsSize[storageType], regionSize)
+		if err != nil {
+			return costs, err
+		}
+		costs[storageType] = cost
+	}
+	return costs, nil
+}
+
+// TotalNoncurrentCost is the part of TotalCost paid for the noncurrent versions
+func (b *Bucket) TotalNoncurrentCost(pricing helpers.Pricing) (float64, error) {
+	costs, err := b.NoncurrentCosts(pricing)
+	if err != nil {
+		return 0.0, err
+	}
+
+	totalCost := 0.0
+	for _, cost := range costs {
+		totalCost += cost.Cost
+	}
+	return math.Round(totalCost*100) / 100, nil
+}
+
+func (b *Bucket) CostByStorageType(pricing helpers.Pricing) (map[string]float64, error) {
+	costs := map[string]float64{}
+	storageCosts, err := b.StorageCosts(pricing)
+	if err != nil {
+		return costs, err
+	}
+
+	for storageType, storageCost := range storageCosts {
+		costs[storageType] = storageCost.Cost
+	}
+	return costs, nil
+}
+
+func (b *Bucket) TotalCost(pricing helpers.Pricing) (float64, error) {
+	costs, err := b.CostByStorageType(pricing)
+	if err != nil {
+		return 0.0, err
+	}
+
 	totalCost := 0.0
+	for _, cost := range costs {
+		totalCost += cost
+	}
+
+	noncurrentCost, err := b.TotalNoncurrentCost(pricing)
+	if err != nil {
+		return 0.0, err
+	}
+	totalCost += noncurrentCost
+
+	multipartUploadsCost, err := b.MultipartUploadsCost(pricing)
+	if err != nil {
+		return 0.0, err
+	}
+	totalCost += multipartUploadsCost
+
+	return math.Round(totalCost*100) / 100, nil
+}
+
+// printOverheads details the storage types billed more than their actual size, and the minimum
+// storage duration left on their objects
+func (b *Bucket) printOverheads(displaySettings DisplaySettings) {
+	pricing := displaySettings.Pricing
+	storageCosts, err := b.StorageCosts(pricing)
+	if err != nil {
+		return
+	}
+
+	for _, storageType := range b.StorageTypes {
+		storageCost := storageCosts[storageType]
+		if storageCost.OverheadCost != 0 || storageCost.BillableBytes != storageCost.ActualBytes {
+			fmt.Printf("    - %v: billed for %v (actual %v), $%v per month of minimum size and metadata overhead\n",
+				storageType,
+				helpers.FormatFileSize(storageCost.BillableBytes+storageCost.StandardMetadataBytes, displaySettings.FileSize),
+				helpers.FormatFileSize(storageCost.ActualBytes, displaySettings.FileSize),
+				storageCost.OverheadCost)
+		}
+
+		if storageCost.MinimumDurationCost > 0 {
+			fmt.Printf("    - %v: $%v of minimum storage duration (%v days) left, billed if the objects are deleted or transitioned now\n",
+				storageType,
+				storageCost.MinimumDurationCost,
+				helpers.MinimumStorageDays(storageType))
+		}
+	}
+}
+
+// IsStale tells if most of the STANDARD bytes have not been modified for more than
+// ScanSettings.StaleDays, making the bucket a candidate for a cheaper storage type
+func (b *Bucket) IsStale() bool {
+	standardSize := b.ObjectsSize["STANDARD"]
+	return standardSize > 0 && b.StaleSize["STANDARD"]*2 > standardSize
+}
+
+// StaleRatio is the share of the STANDARD bytes that are stale
+func (b *Bucket) StaleRatio() float64 {
+	if b.ObjectsSize["STANDARD"] == 0 {
+		return 0
+	}
+	return float64(b.StaleSize["STANDARD"]) / float64(b.ObjectsSize["STANDARD"])
+}
+
+// RequestCounts returns the monthly requests per storage type
+func (b *Bucket) RequestCounts() map[string]helpers.RequestCounts {
+	counts := map[string]helpers.RequestCounts{}
+	for storageType, count := range b.Requests {
+		if storageType == "" {
+			storageType = b.MainStorageType()
+		}
+		counts[storageType] = counts[storageType].Add(count)
+	}
+	return counts
+}
+
+// MainStorageType returns the storage type holding the most objects, STANDARD for empty buckets
+func (b *Bucket) MainStorageType() string {
+	mainStorageType := "STANDARD"
+	mainNumber := 0
 	for _, storageType := range b.StorageTypes {
-		cost, err := helpers.CalculateObjectsCostByStorageType(storageType, b.Region, b.TotalSize(), b.TotalObjectNumber())
+		number := b.ObjectsNumber[storageType]
+		if number > mainNumber || (number == mainNumber && storageType < mainStorageType) {
+			mainStorageType = storageType
+			mainNumber = number
+		}
+	}
+	return mainStorageType
+}
+
+func (b *Bucket) RequestsCostByStorageType(pricing helpers.Pricing) (map[string]float64, error) {
+	costs := map[string]float64{}
+	for storageType, counts := range b.RequestCounts() {
+		cost, err := pricing.CalculateRequestsCost(storageType, b.Region, counts)
 		if err != nil {
-			return 0.0, err
+			return costs, err
 		}
+		costs[storageType] = cost
+	}
+	return costs, nil
+}
+
+func (b *Bucket) TotalRequestsCost(pricing helpers.Pricing) (float64, error) {
+	costs, err := b.RequestsCostByStorageType(pricing)
+	if err != nil {
+		return 0.0, err
+	}
+
+	totalCost := 0.0
+	for _, cost := range costs {
 		totalCost += cost
 	}
-	return totalCost, nil
+	return math.Round(totalCost*100) / 100, nil
 }
 
 func (b *Bucket) Println(displaySettings DisplaySettings) {
+	pricing := displaySettings.Pricing
+
 	fmt.Printf("Name: %v\n", b.Name)
 	fmt.Printf("  - Region: %v\n", b.Region)
 	fmt.Printf("  - CreationDate: %v\n", b.CreationDate.In(displaySettings.Timezone))
@@ -57,9 +312,106 @@ func (b *Bucket) Println(displaySettings DisplaySettings) {
 	fmt.Printf("  - Most recent modified date: %v\n", b.MostRecentModifiedDate.In(displaySettings.Timezone))
 	fmt.Printf("  - Storage types: %v\n", b.StorageTypes)
 
-	totalCost, err := b.TotalCost()
+	totalCost, err := b.TotalCost(pricing)
 	if err != nil {
-		panic(err)
+		fmt.Printf("  - Cost: unavailable (%v)\n", err)
+	} else {
+		fmt.Printf("  - Cost: $%v per month (only for storage)\n", totalCost)
+		b.printOverheads(displaySettings)
+	}
+
+	if b.TotalNoncurrentObjectNumber() > 0 || b.DeleteMarkers > 0 {
+		noncurrentCost, err := b.TotalNoncurrentCost(pricing)
+		cost := fmt.Sprintf("$%v per month (included above)", noncurrentCost)
+		if err != nil {
+			cost = fmt.Sprintf("cost unavailable (%v)", err)
+		}
+		fmt.Printf("  - Noncurrent versions: %v versions (%v), %v\n", b.TotalNoncurrentObjectNumber(), helpers.FormatFileSize(b.TotalNoncurrentSize(), displaySettings.FileSize), cost)
+		for _, storageType := range slices.Sorted(maps.Keys(b.NoncurrentObjectsSize)) {
+			fmt.Printf("    - %v: %v versions (%v)\n", storageType, b.NoncurrentObjectsNumber[storageType], helpers.FormatFileSize(b.NoncurrentObjectsSize[storageType], displaySettings.FileSize))
+		}
+		fmt.Printf("  - Delete markers: %v\n", b.DeleteMarkers)
+	}
+
+	b.printMultipartUploads(displaySettings)
+
+	if len(b.Requests) > 0 {
+		requestsCost, err := b.TotalRequestsCost(pricing)
+		if err != nil {
+			fmt.Printf("  - Requests cost: unavailable (%v)\n", err)
+		} else {
+			requests := helpers.RequestCounts{}
+			for _, counts := range b.Requests {
+				requests = requests.Add(counts)
+			}
+			fmt.Printf("  - Requests cost: $%v per month (%v tier 1 and %v tier 2 requests)\n", requestsCost, requests.Tier1, requests.Tier2)
+		}
+	}
+
+	if b.TotalObjectNumber() > 0 {
+		fmt.Printf("  - Size distribution:\n")
+		for _, storageType := range b.StorageTypes {
+			fmt.Printf("    - %v:\n", storageType)
+			b.SizeHistograms[storageType].Println(helpers.SizeHistogramRanges, displaySettings, "      ")
+		}
+
+		fmt.Printf("  - Age distribution (since last modified):\n")
+		for _, storageType := range b.StorageTypes {
+			fmt.Printf("    - %v:\n", storageType)
+			b.AgeHistograms[storageType].Println(helpers.AgeHistogramRanges, displaySettings, "      ")
+		}
+	}
+
+	if b.LargestObjects.Len() > 0 {
+		fmt.Printf("  - Largest objects:\n")
+		for _, object := range b.LargestObjects.Sorted() {
+			object.Println(displaySettings, "    ")
+		}
+
+		fmt.Printf("  - Oldest objects:\n")
+		for _, object := range b.OldestObjects.Sorted() {
+			object.Println(displaySettings, "    ")
+		}
+	}
+
+	if len(b.Prefixes.Children) > 0 {
+		costs, err := b.CostByStorageType(pricing)
+		if err != nil {
+			costs = nil
+		}
+		fmt.Printf("  - Prefixes (largest first):\n")
+		b.Prefixes.Println(costs, b.ObjectsSize, displaySettings, "    ")
+	}
+
+	if b.IsStale() {
+		fmt.Printf("  - Stale: %.0f%% of the STANDARD bytes have not been modified in over %v days\n", b.StaleRatio()*100, b.StaleDays())
+	}
+
+	if displaySettings.Recommendations {
+		recommendations, err := b.Recommendations(pricing)
+		if err != nil {
+			fmt.Printf("  - Recommendations: unavailable (%v)\n", err)
+		} else if len(recommendations) > 0 {
+			fmt.Printf("  - Recommendations:\n")
+			for _, recommendation := range recommendations {
+				fmt.Printf("    - %v\n", recommendation.Description)
+			}
+		}
+	}
+
+	if slices.Contains(b.StorageTypes, "INTELLIGENT_TIERING") {
+		split, inferred := b.IntelligentTieringSplit(pricing)
+		source := "configured"
+		if inferred {
+			source = "inferred from last modified dates"
+		}
+		fmt.Printf("  - Intelligent-Tiering assumptions: %v (%v), %v monitored objects\n", split, source, b.MonitoredObjectsNumber())
+	}
+
+	if b.HasErrors() {
+		fmt.Printf("  - Errors (partial results):\n")
+		for _, err := range b.Errors {
+			fmt.Printf("    - %v\n", err)
+		}
 	}
-	fmt.Printf("  - Cost: $%v per month (only for storage)\n", totalCost)
 }
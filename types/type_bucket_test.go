@@ -0,0 +1,182 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+func TestBucketCostByStorageType(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+
+	bucket := &Bucket{Name: "mixed", Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{})}
+	bucket.AddObject(ObjectRecord{Key: "standard", StorageType: "STANDARD", Size: 100 * gb, LastModified: time.Now()})
+	bucket.AddObject(ObjectRecord{Key: "glacier", StorageType: "GLACIER", Size: 1000 * gb, LastModified: time.Now()})
+
+	costs, err := bucket.CostByStorageType(helpers.Pricing{})
+	if err != nil {
+		t.Fatalf("CostByStorageType() returned an error: %s", err)
+	}
+
+	// Each storage type is billed on its own size only
+	expected := map[string]float64{"STANDARD": 2.3, "GLACIER": 3.6}
+	for storageType, cost := range expected {
+		if costs[storageType] != cost {
+			t.Errorf("CostByStorageType()[%s] == %v, want %v", storageType, costs[storageType], cost)
+		}
+	}
+
+	// The GLACIER object was just created, its 90 days of minimum storage duration are left
+	storageCosts, err := bucket.StorageCosts(helpers.Pricing{})
+	if err != nil {
+		t.Fatalf("StorageCosts() returned an error: %s", err)
+	}
+	if storageCosts["GLACIER"].MinimumDurationCost != 10.8 || storageCosts["STANDARD"].MinimumDurationCost != 0 {
+		t.Errorf("StorageCosts() minimum duration costs == %v and %v, want 10.8 and 0", storageCosts["GLACIER"].MinimumDurationCost, storageCosts["STANDARD"].MinimumDurationCost)
+	}
+}
+
+func TestBucketIntelligentTieringCost(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+
+	bucket := &Bucket{Name: "media", Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{})}
+	bucket.AddObject(ObjectRecord{Key: "video.mp4", StorageType: "INTELLIGENT_TIERING", Size: 100 * gb, LastModified: time.Now().AddDate(0, 0, -200)})
+	// 100,000 objects under 128 KB adding up to 10 GB
+	bucket.ObjectsNumber["INTELLIGENT_TIERING"] += 100000
+	bucket.ObjectsSize["INTELLIGENT_TIERING"] += 10 * gb
+	bucket.SmallObjectsNumber["INTELLIGENT_TIERING"] = 100000
+	bucket.SmallObjectsSize["INTELLIGENT_TIERING"] = 10 * gb
+
+	// The split is only inferred from the monitored object
+	split, _ := bucket.IntelligentTieringSplit(helpers.Pricing{})
+	if len(split) != 1 || split[helpers.ArchiveInstantAccess] != 1 {
+		t.Errorf("IntelligentTieringSplit() == %v, want archive instant access 100%%", split)
+	}
+
+	// 100 GB in Archive Instant Access and the 10 GB of small objects in Frequent Access, whatever
+	// the split, with the monitoring fee of the single monitored object
+	configured := helpers.Pricing{IntelligentTieringSplit: helpers.IntelligentTieringSplit{helpers.ArchiveInstantAccess: 1}}
+	for _, pricing := range []helpers.Pricing{{}, configured} {
+		costs, err := bucket.StorageCosts(pricing)
+		if err != nil {
+			t.Fatalf("StorageCosts() returned an error: %s", err)
+		}
+		if cost := costs["INTELLIGENT_TIERING"].Cost; cost != 0.63 {
+			t.Errorf("StorageCosts()[INTELLIGENT_TIERING] with the split %v == %v, want 0.63", pricing.IntelligentTieringSplit, cost)
+		}
+	}
+}
+
+func TestShareRegionUsage(t *testing.T) {
+	const tb = 1024 * 1024 * 1024 * 1024
+
+	buckets := []*Bucket{}
+	for _, name := range []string{"first", "second"} {
+		bucket := &Bucket{Name: name, Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{})}
+		bucket.AddObject(ObjectRecord{Key: name, StorageType: "STANDARD", Size: 50 * tb, LastModified: time.Now()})
+		buckets = append(buckets, bucket)
+	}
+
+	ShareRegionUsage(buckets)
+
+	// 100 TB in the region: 50 TB at 0.023 and 50 TB at 0.022, split evenly
+	for _, bucket := range buckets {
+		cost, err := bucket.TotalCost(helpers.Pricing{})
+		if err != nil {
+			t.Fatalf("TotalCost() returned an error: %s", err)
+		}
+		if cost != 1152 {
+			t.Errorf("TotalCost() for %s == %v, want 1152", bucket.Name, cost)
+		}
+	}
+
+	// The noncurrent versions and the multipart uploads count in the region usage as well
+	versioned := &Bucket{Name: "versioned", Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{})}
+	versioned.AddNoncurrentVersion(ObjectRecord{Key: "old", StorageType: "STANDARD", Size: 25 * tb, LastModified: time.Now()})
+	versioned.AddMultipartUpload(MultipartUpload{Key: "upload", StorageType: "STANDARD", Initiated: time.Now(), Parts: 1, Size: 25 * tb})
+	buckets = []*Bucket{buckets[0], versioned}
+
+	ShareRegionUsage(buckets)
+
+	for _, bucket := range buckets {
+		cost, err := bucket.TotalCost(helpers.Pricing{})
+		if err != nil {
+			t.Fatalf("TotalCost() returned an error: %s", err)
+		}
+		if cost != 1152 {
+			t.Errorf("TotalCost() for %s with noncurrent versions and uploads == %v, want 1152", bucket.Name, cost)
+		}
+	}
+}
+
+func TestBucketIsStale(t *testing.T) {
+	old := time.Now().AddDate(0, 0, -200)
+
+	cases := []struct {
+		name     string
+		objects  map[string]time.Time
+		expected bool
+	}{
+		{ // 3 of 4 bytes are old
+			name:     "stale",
+			objects:  map[string]time.Time{"a": old, "b": old, "c": old, "d": time.Now()},
+			expected: true,
+		},
+		{ // Half is not most
+			name:     "half",
+			objects:  map[string]time.Time{"a": old, "b": time.Now()},
+			expected: false,
+		},
+		{
+			name:     "empty",
+			objects:  map[string]time.Time{},
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		bucket := &Bucket{Name: c.name, Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{})}
+		for key, lastModified := range c.objects {
+			bucket.AddObject(ObjectRecord{Key: key, StorageType: "STANDARD", Size: 1, LastModified: lastModified})
+		}
+
+		if got := bucket.IsStale(); got != c.expected {
+			t.Errorf("IsStale() for %s == %v, want %v", c.name, got, c.expected)
+		}
+	}
+}
+
+func TestBucketNoncurrentCost(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+
+	stats := NewObjectStats(ScanSettings{})
+	stats.AddObject(ObjectRecord{Key: "data", StorageType: "STANDARD", Size: 100 * gb, LastModified: time.Now()})
+	stats.AddNoncurrentVersion(ObjectRecord{Key: "data", StorageType: "STANDARD", Size: 100 * gb, LastModified: time.Now()})
+	stats.AddNoncurrentVersion(ObjectRecord{Key: "data", StorageType: "STANDARD", Size: 200 * gb, LastModified: time.Now()})
+	stats.DeleteMarkers = 3
+
+	bucket := &Bucket{Name: "versioned", Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{})}
+	bucket.MergeStats(stats)
+
+	if bucket.TotalNoncurrentObjectNumber() != 2 || bucket.TotalNoncurrentSize() != 300*gb || bucket.DeleteMarkers != 3 {
+		t.Errorf("noncurrent versions == %v of %v bytes and %v delete markers, want 2 of %v and 3", bucket.TotalNoncurrentObjectNumber(), bucket.TotalNoncurrentSize(), bucket.DeleteMarkers, 300*gb)
+	}
+
+	noncurrentCost, err := bucket.TotalNoncurrentCost(helpers.Pricing{})
+	if err != nil {
+		t.Fatalf("TotalNoncurrentCost() returned an error: %s", err)
+	}
+	if noncurrentCost != 6.9 {
+		t.Errorf("TotalNoncurrentCost() == %v, want 6.9", noncurrentCost)
+	}
+
+	// The current versions are billed on top of the noncurrent ones
+	totalCost, err := bucket.TotalCost(helpers.Pricing{})
+	if err != nil {
+		t.Fatalf("TotalCost() returned an error: %s", err)
+	}
+	if totalCost != 9.2 {
+		t.Errorf("TotalCost() == %v, want 9.2", totalCost)
+	}
+}
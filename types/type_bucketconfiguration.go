@@ -0,0 +1,245 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// BucketConfiguration holds the security related settings of a bucket. A nil field means the
+// bucket has no such configuration, the settings that could not be fetched are listed in Missing.
+type BucketConfiguration struct {
+	PublicAccessBlock *s3types.PublicAccessBlockConfiguration
+	// Policy document, nil when the bucket has no policy
+	Policy       *string
+	PolicyStatus *s3types.PolicyStatus
+	Grants       []s3types.Grant
+	Owner        *s3types.Owner
+	// Empty when the bucket has no ownership controls
+	ObjectOwnership s3types.ObjectOwnership
+	Encryption      *s3types.ServerSideEncryptionConfiguration
+	// Empty when versioning was never enabled
+	Versioning s3types.BucketVersioningStatus
+	MFADelete  s3types.MFADeleteStatus
+	ObjectLock *s3types.ObjectLockConfiguration
+	Logging    *s3types.LoggingEnabled
+	// Set like the lifecycle rules of the bucket
+	HasLifecycle bool
+
+	// Checks whose setting could not be fetched, the reason being in the bucket errors
+	Missing []string
+}
+
+// ACL grantees of the predefined groups
+const (
+	allUsersURI           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersURI = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// Finding statuses, from the best to the worst
+const (
+	FindingPass = "pass"
+	FindingWarn = "warn"
+	FindingFail = "fail"
+)
+
+// Checks of Bucket.Findings and AnalyzePolicy, the ones the rules of a profile can use
+var findingChecks = []string{
+	"public-access-block",
+	"policy",
+	"policy-syntax",
+	"policy-wildcard-principal",
+	"policy-cross-account",
+	"policy-broad-actions",
+	"policy-secure-transport",
+	"acl",
+	"ownership",
+	"encryption",
+	"versioning",
+	"mfa-delete",
+	"versioning-lifecycle",
+	"object-lock",
+	"logging",
+	"lifecycle",
+}
+
+// Finding is the result of one check of a bucket configuration
+type Finding struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	// Statement of the bucket policy the finding is tied to, its position like #2 when it has no Sid
+	Sid     string `json:"sid,omitempty"`
+	Message string `json:"message"`
+}
+
+// Findings checks the configuration of a bucket, the checks whose setting is missing are skipped
+func (b *Bucket) Findings(trustedAccounts []string) []Finding {
+	c := b.Configuration
+	if c == nil {
+		return []Finding{}
+	}
+
+	findings := []Finding{}
+	add := func(check, status, message string) {
+		if !c.isMissing(check) {
+			findings = append(findings, Finding{Check: check, Status: status, Message: message})
+		}
+	}
+
+	if block := c.PublicAccessBlock; block == nil {
+		// The account-level block (s3control GetPublicAccessBlock) is not fetched and may still block public access
+		add("public-access-block", FindingWarn, "no bucket public access block (account-level block not checked)")
+	} else {
+		disabled := []string{}
+		for name, enabled := range map[string]*bool{
+			"BlockPublicAcls":       block.BlockPublicAcls,
+			"IgnorePublicAcls":      block.IgnorePublicAcls,
+			"BlockPublicPolicy":     block.BlockPublicPolicy,
+			"RestrictPublicBuckets": block.RestrictPublicBuckets,
+		} {
+			if !aws.ToBool(enabled) {
+				disabled = append(disabled, name)
+			}
+		}
+		if len(disabled) == 0 {
+			add("public-access-block", FindingPass, "public access fully blocked")
+		} else {
+			slices.Sort(disabled)
+			add("public-access-block", FindingWarn, fmt.Sprintf("public access block partially disabled (%v)", strings.Join(disabled, ", ")))
+		}
+	}
+
+	switch {
+	case c.Policy == nil:
+		add("policy", FindingPass, "no bucket policy")
+	case c.isMissing("policy-status"):
+		// Only AWS tells if the policy is public, its statements are still checked below
+	case c.PolicyStatus != nil && aws.ToBool(c.PolicyStatus.IsPublic):
+		add("policy", FindingFail, "public access via policy")
+	default:
+		add("policy", FindingPass, "bucket policy is not public")
+	}
+
+	if !c.isMissing("policy") {
+		if policy, err := c.parsePolicy(); err != nil {
+			add("policy-syntax", FindingWarn, err.Error())
+		} else {
+			findings = append(findings, AnalyzePolicy(policy, trustedAccounts)...)
+		}
+	}
+
+	add(c.aclFinding())
+
+	if c.ObjectOwnership == s3types.ObjectOwnershipBucketOwnerEnforced {
+		add("ownership", FindingPass, "ACLs disabled (BucketOwnerEnforced)")
+	} else {
+		ownership := string(c.ObjectOwnership)
+		if ownership == "" {
+			ownership = "no ownership controls"
+		}
+		add("ownership", FindingWarn, fmt.Sprintf("ACLs enabled (%v)", ownership))
+	}
+
+	if algorithm := c.encryptionAlgorithm(); algorithm == "" {
+		add("encryption", FindingFail, "no default encryption")
+	} else {
+		add("encryption", FindingPass, fmt.Sprintf("default encryption with %v", algorithm))
+	}
+
+	switch c.Versioning {
+	case s3types.BucketVersioningStatusEnabled:
+		add("versioning", FindingPass, "versioning enabled")
+		if b.expiresNoncurrentVersions() {
+			add("versioning-lifecycle", FindingPass, "lifecycle for noncurrent versions")
+		} else {
+			add("versioning-lifecycle", FindingWarn, "versioning without lifecycle for noncurrent versions")
+		}
+	case s3types.BucketVersioningStatusSuspended:
+		add("versioning", FindingWarn, "versioning suspended")
+		add("versioning-lifecycle", FindingPass, "versioning not enabled")
+	default:
+		add("versioning", FindingWarn, "versioning disabled")
+		add("versioning-lifecycle", FindingPass, "versioning not enabled")
+	}
+
+	// MFA delete can only be enabled along with versioning, an unversioned bucket does not have it
+	if c.MFADelete == s3types.MFADeleteStatusEnabled {
+		add("mfa-delete", FindingPass, "MFA delete enabled")
+	} else {
+		add("mfa-delete", FindingWarn, "MFA delete disabled")
+	}
+
+	if c.ObjectLock != nil && c.ObjectLock.ObjectLockEnabled == s3types.ObjectLockEnabledEnabled {
+		add("object-lock", FindingPass, "object lock enabled")
+	} else {
+		add("object-lock", FindingWarn, "object lock disabled")
+	}
+
+	if c.Logging != nil {
+		add("logging", FindingPass, fmt.Sprintf("server access logging to %v", aws.ToString(c.Logging.TargetBucket)))
+	} else {
+		add("logging", FindingWarn, "no server access logging")
+	}
+
+	if c.HasLifecycle {
+		add("lifecycle", FindingPass, fmt.Sprintf("%v lifecycle rules", len(b.LifecycleRules)))
+	} else {
+		add("lifecycle", FindingWarn, "no lifecycle configuration")
+	}
+
+	return findings
+}
+
+func (c *BucketConfiguration) isMissing(check string) bool {
+	return slices.Contains(c.Missing, check)
+}
+
+// parsePolicy returns an empty policy when the bucket has none
+func (c *BucketConfiguration) parsePolicy() (helpers.Policy, error) {
+	if c.Policy == nil {
+		return helpers.Policy{}, nil
+	}
+	return helpers.ParsePolicy(*c.Policy)
+}
+
+func (c *BucketConfiguration) aclFinding() (string, string, string) {
+	for _, grant := range c.Grants {
+		if grant.Grantee == nil {
+			continue
+		}
+		switch aws.ToString(grant.Grantee.URI) {
+		case allUsersURI:
+			return "acl", FindingFail, fmt.Sprintf("public %v via ACL", strings.ToLower(string(grant.Permission)))
+		case authenticatedUsersURI:
+			return "acl", FindingFail, fmt.Sprintf("%v for any AWS account via ACL", strings.ToLower(string(grant.Permission)))
+		}
+	}
+	return "acl", FindingPass, "no public ACL grant"
+}
+
+func (c *BucketConfiguration) encryptionAlgorithm() string {
+	if c.Encryption == nil {
+		return ""
+	}
+	for _, rule := range c.Encryption.Rules {
+		if rule.ApplyServerSideEncryptionByDefault != nil {
+			return string(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm)
+		}
+	}
+	return ""
+}
+
+// expiresNoncurrentVersions tells if an enabled lifecycle rule expires or transitions noncurrent versions
+func (b *Bucket) expiresNoncurrentVersions() bool {
+	for _, rule := range b.LifecycleRules {
+		if rule.Status == s3types.ExpirationStatusEnabled && (rule.NoncurrentVersionExpiration != nil || len(rule.NoncurrentVersionTransitions) > 0) {
+			return true
+		}
+	}
+	return false
+}
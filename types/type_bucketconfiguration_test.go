@@ -0,0 +1,95 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestBucketFindings(t *testing.T) {
+	bucket := &Bucket{Name: "website", Region: "us-east-1", Configuration: &BucketConfiguration{
+		PublicAccessBlock: &s3types.PublicAccessBlockConfiguration{
+			BlockPublicAcls:       aws.Bool(true),
+			IgnorePublicAcls:      aws.Bool(true),
+			BlockPublicPolicy:     aws.Bool(false),
+			RestrictPublicBuckets: aws.Bool(false),
+		},
+		Policy:       aws.String("{}"),
+		PolicyStatus: &s3types.PolicyStatus{IsPublic: aws.Bool(true)},
+		Grants: []s3types.Grant{{
+			Grantee:    &s3types.Grantee{Type: s3types.TypeGroup, URI: aws.String(allUsersURI)},
+			Permission: s3types.PermissionRead,
+		}},
+		Versioning: s3types.BucketVersioningStatusEnabled,
+		// The lifecycle configuration could not be fetched
+		Missing: []string{"lifecycle", "versioning-lifecycle"},
+	}}
+
+	want := map[string]Finding{
+		"public-access-block": {Check: "public-access-block", Status: FindingWarn, Message: "public access block partially disabled (BlockPublicPolicy, RestrictPublicBuckets)"},
+		"policy":              {Check: "policy", Status: FindingFail, Message: "public access via policy"},
+		// The policy has no statement, like a bucket without policy
+		"policy-wildcard-principal": {Check: "policy-wildcard-principal", Status: FindingPass, Message: "no wildcard principal"},
+		"policy-cross-account":      {Check: "policy-cross-account", Status: FindingPass, Message: "no access granted to untrusted accounts"},
+		"policy-broad-actions":      {Check: "policy-broad-actions", Status: FindingPass, Message: "no statement allows every S3 action"},
+		"policy-secure-transport":   {Check: "policy-secure-transport", Status: FindingWarn, Message: "no bucket policy to deny requests without aws:SecureTransport"},
+		"acl":                       {Check: "acl", Status: FindingFail, Message: "public read via ACL"},
+		"ownership":                 {Check: "ownership", Status: FindingWarn, Message: "ACLs enabled (no ownership controls)"},
+		"encryption":                {Check: "encryption", Status: FindingFail, Message: "no default encryption"},
+		"versioning":                {Check: "versioning", Status: FindingPass, Message: "versioning enabled"},
+		"mfa-delete":                {Check: "mfa-delete", Status: FindingWarn, Message: "MFA delete disabled"},
+		"object-lock":               {Check: "object-lock", Status: FindingWarn, Message: "object lock disabled"},
+		"logging":                   {Check: "logging", Status: FindingWarn, Message: "no server access logging"},
+	}
+
+	findings := bucket.Findings(nil)
+	if len(findings) != len(want) {
+		t.Errorf("Findings() returned %v findings, want %v: %v", len(findings), len(want), findings)
+	}
+	for _, finding := range findings {
+		if finding != want[finding.Check] {
+			t.Errorf("Findings() %v == %v, want %v", finding.Check, finding, want[finding.Check])
+		}
+	}
+
+	// Versioning without a rule for the noncurrent versions
+	bucket.Configuration.Missing = nil
+	bucket.Configuration.HasLifecycle = true
+	bucket.LifecycleRules = []s3types.LifecycleRule{{
+		Status:                         s3types.ExpirationStatusEnabled,
+		AbortIncompleteMultipartUpload: &s3types.AbortIncompleteMultipartUpload{DaysAfterInitiation: aws.Int32(7)},
+	}}
+	audit := bucket.Audit(nil)
+	if audit.Count(FindingWarn) != 7 {
+		t.Errorf("Count(warn) == %v, want 7: %v", audit.Count(FindingWarn), audit.Findings)
+	}
+
+	bucket.LifecycleRules = append(bucket.LifecycleRules, s3types.LifecycleRule{
+		Status:                      s3types.ExpirationStatusEnabled,
+		NoncurrentVersionExpiration: &s3types.NoncurrentVersionExpiration{NoncurrentDays: aws.Int32(30)},
+	})
+	for _, finding := range bucket.Findings(nil) {
+		if finding.Check == "versioning-lifecycle" && finding.Status != FindingPass {
+			t.Errorf("Findings() warned about versioning-lifecycle with a noncurrent expiration rule")
+		}
+	}
+
+	// Without a bucket public access block nor the policy status, the policy is still analyzed
+	bucket.Configuration.PublicAccessBlock = nil
+	bucket.Configuration.PolicyStatus = nil
+	bucket.Configuration.Missing = []string{"policy-status"}
+	checks := map[string]Finding{}
+	for _, finding := range bucket.Findings(nil) {
+		checks[finding.Check] = finding
+	}
+	if finding := checks["public-access-block"]; finding.Status != FindingWarn {
+		t.Errorf("Findings() public-access-block == %v, want a warn as the account-level block is unknown", finding)
+	}
+	if finding, ok := checks["policy"]; ok {
+		t.Errorf("Findings() returned %v without the policy status", finding)
+	}
+	if _, ok := checks["policy-secure-transport"]; !ok {
+		t.Errorf("Findings() did not analyze the policy without the policy status")
+	}
+}
@@ -0,0 +1,144 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// Condition keys limiting a wildcard principal to known networks, organizations, accounts or
+// resources. Other keys, such as aws:SecureTransport or s3:prefix, leave the grant public.
+var restrictingConditionKeys = []string{
+	"aws:SourceVpce",
+	"aws:SourceVpc",
+	"aws:SourceIp",
+	"aws:VpcSourceIp",
+	"aws:PrincipalOrgID",
+	"aws:PrincipalOrgPaths",
+	"aws:PrincipalAccount",
+	"aws:PrincipalArn",
+	"aws:SourceAccount",
+	"aws:SourceArn",
+	"aws:SourceOrgID",
+	"aws:SourceOrgPaths",
+}
+
+// AnalyzePolicy checks the statements of a bucket policy for public and cross-account grants, broad
+// actions and the enforcement of TLS. The accounts of trustedAccounts may be granted access, an
+// empty policy stands for a bucket without policy.
+func AnalyzePolicy(policy helpers.Policy, trustedAccounts []string) []Finding {
+	findings := []Finding{}
+	secureTransportSid := ""
+	enforcesSecureTransport := false
+	// The checks passing on every statement get a single pass finding, so that rules know they were evaluated
+	failing := map[string]bool{}
+
+	for i, statement := range policy.Statement {
+		sid := helpers.PolicyStatementName(statement, i)
+
+		if statement.Effect == "Deny" {
+			if !enforcesSecureTransport && deniesInsecureTransport(statement) {
+				secureTransportSid, enforcesSecureTransport = sid, true
+			}
+			continue
+		}
+
+		// Allowing everyone but the NotPrincipal is as open as a wildcard
+		if (statement.Principal != nil && statement.Principal.IsWildcard()) || statement.NotPrincipal != nil {
+			failing["policy-wildcard-principal"] = true
+			if keys := restrictingKeys(statement); len(keys) > 0 {
+				findings = append(findings, Finding{Check: "policy-wildcard-principal", Status: FindingWarn, Sid: sid, Message: fmt.Sprintf("wildcard principal limited by conditions (%v)", strings.Join(keys, ", "))})
+			} else {
+				findings = append(findings, Finding{Check: "policy-wildcard-principal", Status: FindingFail, Sid: sid, Message: fmt.Sprintf("public %v via policy", describeActions(statement))})
+			}
+		}
+
+		if statement.Principal != nil {
+			accounts := []string{}
+			for _, account := range statement.Principal.Accounts() {
+				if !slices.Contains(trustedAccounts, account) && !slices.Contains(accounts, account) {
+					accounts = append(accounts, account)
+				}
+			}
+			if len(accounts) > 0 {
+				failing["policy-cross-account"] = true
+				findings = append(findings, Finding{Check: "policy-cross-account", Status: FindingFail, Sid: sid, Message: fmt.Sprintf("access granted to untrusted accounts %v", strings.Join(accounts, ", "))})
+			}
+		}
+
+		if isBroadAction(statement) {
+			failing["policy-broad-actions"] = true
+			findings = append(findings, Finding{Check: "policy-broad-actions", Status: FindingWarn, Sid: sid, Message: "allows every S3 action (s3:*)"})
+		}
+	}
+
+	if !failing["policy-wildcard-principal"] {
+		findings = append(findings, Finding{Check: "policy-wildcard-principal", Status: FindingPass, Message: "no wildcard principal"})
+	}
+	if !failing["policy-cross-account"] {
+		findings = append(findings, Finding{Check: "policy-cross-account", Status: FindingPass, Message: "no access granted to untrusted accounts"})
+	}
+	if !failing["policy-broad-actions"] {
+		findings = append(findings, Finding{Check: "policy-broad-actions", Status: FindingPass, Message: "no statement allows every S3 action"})
+	}
+
+	switch {
+	case enforcesSecureTransport:
+		findings = append(findings, Finding{Check: "policy-secure-transport", Status: FindingPass, Sid: secureTransportSid, Message: "requests without TLS are denied"})
+	case len(policy.Statement) == 0:
+		// Nothing to fix in a statement, a policy has to be added
+		findings = append(findings, Finding{Check: "policy-secure-transport", Status: FindingWarn, Message: "no bucket policy to deny requests without aws:SecureTransport"})
+	default:
+		findings = append(findings, Finding{Check: "policy-secure-transport", Status: FindingFail, Message: "no statement denies requests without aws:SecureTransport"})
+	}
+
+	return findings
+}
+
+// restrictingKeys returns the condition keys of a statement that limit who can use it
+func restrictingKeys(statement helpers.PolicyStatement) []string {
+	keys := []string{}
+	for _, key := range statement.ConditionKeys() {
+		if slices.ContainsFunc(restrictingConditionKeys, func(restricting string) bool { return strings.EqualFold(key, restricting) }) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// deniesInsecureTransport tells if a Deny statement applies to everyone, for every S3 action, when
+// aws:SecureTransport is false
+func deniesInsecureTransport(statement helpers.PolicyStatement) bool {
+	if statement.Principal == nil || !statement.Principal.IsWildcard() || !statement.MatchesAction("s3:*") {
+		return false
+	}
+	return slices.ContainsFunc(statement.ConditionValues("Bool", "aws:SecureTransport"), func(value string) bool {
+		return strings.EqualFold(value, "false")
+	})
+}
+
+// isBroadAction tells if an Allow statement grants every S3 action, with a wildcard or a NotAction
+func isBroadAction(statement helpers.PolicyStatement) bool {
+	if len(statement.NotAction) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(statement.Action, func(action string) bool {
+		return action == "*" || strings.EqualFold(action, "s3:*")
+	})
+}
+
+// describeActions summarizes the actions of a statement, e.g. read for s3:GetObject
+func describeActions(statement helpers.PolicyStatement) string {
+	switch {
+	case isBroadAction(statement):
+		return "full access"
+	case statement.MatchesAction("s3:PutObject") || statement.MatchesAction("s3:DeleteObject"):
+		return "write"
+	case statement.MatchesAction("s3:GetObject") || statement.MatchesAction("s3:ListBucket"):
+		return "read"
+	default:
+		return "access"
+	}
+}
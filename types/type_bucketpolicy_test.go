@@ -0,0 +1,87 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+func TestAnalyzePolicy(t *testing.T) {
+	policy, err := helpers.ParsePolicy(`{"Version": "2012-10-17", "Statement": [
+		{"Sid": "PublicRead", "Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::website/*"},
+		{"Sid": "VpcOnly", "Effect": "Allow", "Principal": {"AWS": "*"}, "Action": ["s3:GetObject", "s3:PutObject"], "Resource": "arn:aws:s3:::website/*",
+			"Condition": {"StringEquals": {"aws:SourceVpce": "vpce-1a2b3c4d"}}},
+		{"Sid": "PublicListing", "Effect": "Allow", "Principal": "*", "Action": "s3:ListBucket", "Resource": "arn:aws:s3:::website",
+			"Condition": {"StringLike": {"s3:prefix": "public/*"}}},
+		{"Sid": "Partners", "Effect": "Allow", "Principal": {"AWS": ["arn:aws:iam::111122223333:root", "arn:aws:iam::444455556666:role/reader", "777788889999"]}, "Action": "s3:*", "Resource": "*"},
+		{"Effect": "Allow", "Principal": {"Service": "logging.s3.amazonaws.com"}, "Action": "s3:PutObject", "Resource": "arn:aws:s3:::website/logs/*"}
+	]}`)
+	if err != nil {
+		t.Fatalf("ParsePolicy() returned an error: %s", err)
+	}
+
+	expected := []Finding{
+		{Check: "policy-wildcard-principal", Status: FindingFail, Sid: "PublicRead", Message: "public read via policy"},
+		{Check: "policy-wildcard-principal", Status: FindingWarn, Sid: "VpcOnly", Message: "wildcard principal limited by conditions (aws:SourceVpce)"},
+		// Limiting the keys does not limit who can list them
+		{Check: "policy-wildcard-principal", Status: FindingFail, Sid: "PublicListing", Message: "public read via policy"},
+		{Check: "policy-cross-account", Status: FindingFail, Sid: "Partners", Message: "access granted to untrusted accounts 444455556666, 777788889999"},
+		{Check: "policy-broad-actions", Status: FindingWarn, Sid: "Partners", Message: "allows every S3 action (s3:*)"},
+		{Check: "policy-secure-transport", Status: FindingFail, Message: "no statement denies requests without aws:SecureTransport"},
+	}
+
+	findings := AnalyzePolicy(policy, []string{"111122223333"})
+	if len(findings) != len(expected) {
+		t.Fatalf("AnalyzePolicy() returned %v findings, want %v: %v", len(findings), len(expected), findings)
+	}
+	for i, finding := range findings {
+		if finding != expected[i] {
+			t.Errorf("AnalyzePolicy()[%v] == %v, want %v", i, finding, expected[i])
+		}
+	}
+}
+
+func TestAnalyzePolicySecureTransport(t *testing.T) {
+	policy, err := helpers.ParsePolicy(`{"Statement": {"Effect": "Deny", "Principal": "*", "Action": "s3:*", "Resource": ["arn:aws:s3:::bucket", "arn:aws:s3:::bucket/*"],
+		"Condition": {"Bool": {"aws:SecureTransport": false}}}}`)
+	if err != nil {
+		t.Fatalf("ParsePolicy() returned an error: %s", err)
+	}
+
+	// The checks without a failing statement pass once
+	expected := []Finding{
+		{Check: "policy-wildcard-principal", Status: FindingPass, Message: "no wildcard principal"},
+		{Check: "policy-cross-account", Status: FindingPass, Message: "no access granted to untrusted accounts"},
+		{Check: "policy-broad-actions", Status: FindingPass, Message: "no statement allows every S3 action"},
+		{Check: "policy-secure-transport", Status: FindingPass, Sid: "#1", Message: "requests without TLS are denied"},
+	}
+	findings := AnalyzePolicy(policy, nil)
+	if len(findings) != len(expected) {
+		t.Fatalf("AnalyzePolicy() returned %v findings, want %v: %v", len(findings), len(expected), findings)
+	}
+	for i, finding := range findings {
+		if finding != expected[i] {
+			t.Errorf("AnalyzePolicy()[%v] == %v, want %v", i, finding, expected[i])
+		}
+	}
+
+	// A bucket without policy has no statement to fix
+	if findings := AnalyzePolicy(helpers.Policy{}, nil); secureTransport(findings).Status != FindingWarn {
+		t.Errorf("AnalyzePolicy(no policy) == %v, want a warning policy-secure-transport", findings)
+	}
+
+	// Denying only the uploads does not protect the reads
+	policy.Statement[0].Action = helpers.PolicyValues{"s3:PutObject"}
+	if findings := AnalyzePolicy(policy, nil); secureTransport(findings).Status != FindingFail {
+		t.Errorf("AnalyzePolicy() == %v, want a failing policy-secure-transport", findings)
+	}
+}
+
+func secureTransport(findings []Finding) Finding {
+	for _, finding := range findings {
+		if finding.Check == "policy-secure-transport" {
+			return finding
+		}
+	}
+	return Finding{}
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Violation is a rule not satisfied by a bucket
+type Violation struct {
+	RuleID      string `json:"ruleId"`
+	Severity    string `json:"severity"`
+	Bucket      string `json:"bucket"`
+	Region      string `json:"region"`
+	Description string `json:"description"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation"`
+}
+
+// RuleResult is the outcome of a rule on every bucket
+type RuleResult struct {
+	ID          string `json:"id"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	Remediation string `json:"remediation"`
+	// Buckets whose settings needed by the rule could be fetched
+	EvaluatedBuckets int         `json:"evaluatedBuckets"`
+	Violations       []Violation `json:"violations"`
+}
+
+// Evaluate runs the rules against every bucket, in the order of the rules. The policies may grant
+// access to the trustedAccounts.
+func Evaluate(rules []Rule, buckets []*Bucket, trustedAccounts []string) []RuleResult {
+	// The findings of a bucket are shared by every rule
+	findings := make([][]Finding, len(buckets))
+	for i, bucket := range buckets {
+		findings[i] = bucket.Findings(trustedAccounts)
+	}
+
+	results := []RuleResult{}
+	for _, rule := range rules {
+		result := RuleResult{
+			ID:          rule.ID(),
+			Severity:    rule.Severity(),
+			Description: rule.Description(),
+			Remediation: rule.Remediation(),
+			Violations:  []Violation{},
+		}
+
+		for i, bucket := range buckets {
+			messages, evaluated := rule.Evaluate(bucket, findings[i])
+			if evaluated {
+				result.EvaluatedBuckets++
+			}
+			for _, message := range messages {
+				result.Violations = append(result.Violations, Violation{
+					RuleID:      rule.ID(),
+					Severity:    rule.Severity(),
+					Bucket:      bucket.Name,
+					Region:      bucket.Region,
+					Description: rule.Description(),
+					Message:     message,
+					Remediation: rule.Remediation(),
+				})
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// PrintRuleResults lists the violations per rule, followed by their number per severity
+func PrintRuleResults(results []RuleResult, buckets int) {
+	severities := map[string]int{}
+	violations := 0
+	for _, result := range results {
+		if len(result.Violations) == 0 {
+			continue
+		}
+		severities[result.Severity] += len(result.Violations)
+		violations += len(result.Violations)
+
+		fmt.Printf("[%v] %v %v: %v violations\n", strings.ToUpper(result.Severity), result.ID, result.Description, len(result.Violations))
+		for _, violation := range result.Violations {
+			fmt.Printf("  - %v: %v\n", violation.Bucket, violation.Message)
+		}
+		fmt.Printf("  Remediation: %v\n", result.Remediation)
+	}
+
+	fmt.Printf("Compliance summary: %v rules evaluated on %v buckets, %v violations (%v critical, %v high, %v medium, %v low)\n", len(results), buckets, violations, severities[SeverityCritical], severities[SeverityHigh], severities[SeverityMedium], severities[SeverityLow])
+	for _, result := range results {
+		if result.EvaluatedBuckets < buckets {
+			fmt.Printf("  - %v could not be evaluated on %v buckets, see the errors\n", result.ID, buckets-result.EvaluatedBuckets)
+		}
+	}
+}
+
+// ComplianceReport is the machine-readable view of the rule results
+type ComplianceReport struct {
+	StartTime string       `json:"startTime"`
+	EndTime   string       `json:"endTime"`
+	Account   string       `json:"account"`
+	Rules     []RuleResult `json:"rules"`
+	Errors    []string     `json:"errors"`
+}
+
+func NewComplianceReport(startTime, endTime time.Time, account string, results []RuleResult, errors []error, displaySettings DisplaySettings) ComplianceReport {
+	return ComplianceReport{
+		StartTime: formatDate(startTime, displaySettings),
+		EndTime:   formatDate(endTime, displaySettings),
+		Account:   account,
+		Rules:     results,
+		Errors:    errorStrings(errors),
+	}
+}
+
+func (r ComplianceReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes one row per violation
+func (r ComplianceReport) WriteCSV(w io.Writer, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	if err := writer.Write([]string{"rule_id", "severity", "bucket", "region", "description", "message", "remediation"}); err != nil {
+		return err
+	}
+
+	for _, result := range r.Rules {
+		for _, violation := range result.Violations {
+			row := []string{violation.RuleID, violation.Severity, violation.Bucket, violation.Region, violation.Description, violation.Message, violation.Remediation}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
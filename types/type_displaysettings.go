@@ -1,9 +1,19 @@
 package types
 
-import "time"
+import (
+	"time"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
 
 type DisplaySettings struct {
 	FileSize int
 	GroupBy  string
 	Timezone *time.Location
+	// Output format of the report: "text", "json", "csv" or "tsv"
+	Output string
+	// Add the storage type recommendations to the report
+	Recommendations bool
+	// Rates and assumptions used to price the buckets
+	Pricing helpers.Pricing
 }
@@ -0,0 +1,170 @@
+package types
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// ObjectLocation is one copy of a duplicated object
+type ObjectLocation struct {
+	Bucket      string `json:"bucket"`
+	Region      string `json:"region"`
+	Key         string `json:"key"`
+	StorageType string `json:"storageType"`
+}
+
+// DuplicateSet is a group of objects sharing the same ETag and size, most likely the same content
+type DuplicateSet struct {
+	ETag string `json:"etag"`
+	Size int    `json:"size"`
+	// Multipart ETags (ending with -N) depend on the part size, copies uploaded with another part
+	// size cannot be matched
+	Multipart bool             `json:"multipart"`
+	Objects   []ObjectLocation `json:"objects"`
+	// Bytes of every copy but one
+	WastedBytes int `json:"wastedBytes"`
+	// Monthly storage cost of every copy but the cheapest one
+	MonthlyCost float64 `json:"monthlyCost"`
+}
+
+type duplicateKey struct {
+	etag string
+	size int
+}
+
+// DuplicateIndex groups the objects of every scanned bucket by ETag and size while they are listed,
+// it is safe for concurrent use by the page workers. Only the first copy of an object is kept until
+// a second one shows up, the objects themselves are not recorded.
+type DuplicateIndex struct {
+	first    map[duplicateKey]ObjectLocation
+	repeated map[duplicateKey][]ObjectLocation
+	lock     sync.Mutex
+}
+
+func NewDuplicateIndex() *DuplicateIndex {
+	return &DuplicateIndex{
+		first:    map[duplicateKey]ObjectLocation{},
+		repeated: map[duplicateKey][]ObjectLocation{},
+	}
+}
+
+// Add indexes an object of a bucket, empty objects are left out
+func (i *DuplicateIndex) Add(bucket *Bucket, object ObjectRecord) {
+	if object.Size == 0 || object.ETag == "" {
+		return
+	}
+
+	key := duplicateKey{etag: object.ETag, size: object.Size}
+	location := ObjectLocation{
+		Bucket:      bucket.Name,
+		Region:      bucket.Region,
+		Key:         object.Key,
+		StorageType: object.StorageType,
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if copies, ok := i.repeated[key]; ok {
+		i.repeated[key] = append(copies, location)
+		return
+	}
+	if first, ok := i.first[key]; ok {
+		delete(i.first, key)
+		i.repeated[key] = []ObjectLocation{first, location}
+		return
+	}
+	i.first[key] = location
+}
+
+// FindDuplicates returns the objects of the index stored more than once, from the set wasting the
+// most bytes to the one wasting the least
+func FindDuplicates(index *DuplicateIndex, pricing helpers.Pricing) ([]DuplicateSet, error) {
+	duplicates := []DuplicateSet{}
+	for key, objects := range index.repeated {
+		set := DuplicateSet{
+			ETag:        key.etag,
+			Size:        key.size,
+			Multipart:   IsMultipartETag(key.etag),
+			Objects:     objects,
+			WastedBytes: key.size * (len(objects) - 1),
+		}
+		slices.SortFunc(set.Objects, func(a, b ObjectLocation) int {
+			return cmp.Or(strings.Compare(a.Bucket, b.Bucket), strings.Compare(a.Key, b.Key))
+		})
+
+		totalCost, cheapestCost := 0.0, math.Inf(1)
+		for _, object := range set.Objects {
+			cost, err := pricing.CalculateObjectCost(object.StorageType, object.Region, key.size)
+			if err != nil {
+				return duplicates, fmt.Errorf("%v/%v: %w", object.Bucket, object.Key, err)
+			}
+			totalCost += cost
+			cheapestCost = min(cheapestCost, cost)
+		}
+		set.MonthlyCost = totalCost - cheapestCost
+
+		duplicates = append(duplicates, set)
+	}
+
+	slices.SortFunc(duplicates, func(a, b DuplicateSet) int {
+		return cmp.Or(cmp.Compare(b.WastedBytes, a.WastedBytes), strings.Compare(a.ETag, b.ETag))
+	})
+	return duplicates, nil
+}
+
+// IsMultipartETag tells if an ETag is the one of a multipart upload, made of the number of parts
+// after a dash instead of the MD5 of the content
+func IsMultipartETag(etag string) bool {
+	index := strings.LastIndexByte(etag, '-')
+	if index == -1 || index == len(etag)-1 {
+		return false
+	}
+	for _, c := range etag[index+1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// PrintDuplicates lists the single part and the multipart duplicate sets separately
+func PrintDuplicates(duplicates []DuplicateSet, displaySettings DisplaySettings) {
+	singlePart, multipart := []DuplicateSet{}, []DuplicateSet{}
+	for _, set := range duplicates {
+		if set.Multipart {
+			multipart = append(multipart, set)
+		} else {
+			singlePart = append(singlePart, set)
+		}
+	}
+
+	printDuplicateSets("Duplicates (same MD5 ETag and size)", singlePart, displaySettings)
+	printDuplicateSets("Multipart duplicates (same multipart ETag and size, only found when uploaded with the same part size)", multipart, displaySettings)
+}
+
+func printDuplicateSets(title string, duplicates []DuplicateSet, displaySettings DisplaySettings) {
+	if len(duplicates) == 0 {
+		return
+	}
+
+	wastedBytes, monthlyCost := 0, 0.0
+	for _, set := range duplicates {
+		wastedBytes += set.WastedBytes
+		monthlyCost += set.MonthlyCost
+	}
+
+	fmt.Printf("%v: %v sets, %v wasted, $%.2f per month\n", title, len(duplicates), helpers.FormatFileSize(wastedBytes, displaySettings.FileSize), monthlyCost)
+	for _, set := range duplicates {
+		fmt.Printf("  - ETag %v, %v copies of %v, %v wasted, $%.2f per month:\n", set.ETag, len(set.Objects), helpers.FormatFileSize(set.Size, displaySettings.FileSize), helpers.FormatFileSize(set.WastedBytes, displaySettings.FileSize), set.MonthlyCost)
+		for _, object := range set.Objects {
+			fmt.Printf("    - %v/%v (%v)\n", object.Bucket, object.Key, object.StorageType)
+		}
+	}
+}
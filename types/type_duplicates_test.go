@@ -0,0 +1,77 @@
+package types
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+
+	first := &Bucket{Name: "first", Region: "us-east-1"}
+	second := &Bucket{Name: "second", Region: "us-east-1"}
+	index := NewDuplicateIndex()
+	for _, object := range []ObjectRecord{
+		{Key: "dataset.csv", StorageType: "STANDARD", Size: 100 * gb, ETag: "d41d8cd98f00b204e9800998ecf8427e"},
+		{Key: "video.mp4", StorageType: "STANDARD", Size: 10 * gb, ETag: "9b2cf535f27731c974343645a3985328-2"},
+		{Key: "empty", StorageType: "STANDARD", Size: 0, ETag: "d41d8cd98f00b204e9800998ecf8427e"},
+	} {
+		object.LastModified = time.Now()
+		index.Add(first, object)
+	}
+	for _, object := range []ObjectRecord{
+		{Key: "copy/dataset.csv", StorageType: "GLACIER", Size: 100 * gb, ETag: "d41d8cd98f00b204e9800998ecf8427e"},
+		{Key: "copy/video.mp4", StorageType: "STANDARD", Size: 10 * gb, ETag: "9b2cf535f27731c974343645a3985328-2"},
+		{Key: "copy/other.mp4", StorageType: "STANDARD", Size: 10 * gb, ETag: "0a2cf535f27731c974343645a3985328-3"},
+		{Key: "empty", StorageType: "STANDARD", Size: 0, ETag: "d41d8cd98f00b204e9800998ecf8427e"},
+	} {
+		object.LastModified = time.Now()
+		index.Add(second, object)
+	}
+
+	// Only the repeated objects are kept, the other video and the empty objects are not
+	if len(index.repeated) != 2 || len(index.first) != 1 {
+		t.Errorf("DuplicateIndex holds %v repeated and %v single objects, want 2 and 1", len(index.repeated), len(index.first))
+	}
+
+	duplicates, err := FindDuplicates(index, helpers.Pricing{})
+	if err != nil {
+		t.Fatalf("FindDuplicates() returned an error: %s", err)
+	}
+	if len(duplicates) != 2 {
+		t.Fatalf("FindDuplicates() returned %d sets, want 2", len(duplicates))
+	}
+
+	// The GLACIER copy is kept, the STANDARD one costs 100 GB at 0.023
+	dataset := duplicates[0]
+	if dataset.Multipart || dataset.WastedBytes != 100*gb || len(dataset.Objects) != 2 || dataset.Objects[0].Bucket != "first" {
+		t.Errorf("FindDuplicates()[0] == %+v, want the single part dataset in both buckets", dataset)
+	}
+	if math.Round(dataset.MonthlyCost*100)/100 != 2.3 {
+		t.Errorf("FindDuplicates()[0].MonthlyCost == %v, want 2.3", dataset.MonthlyCost)
+	}
+
+	video := duplicates[1]
+	if !video.Multipart || video.WastedBytes != 10*gb {
+		t.Errorf("FindDuplicates()[1] == %+v, want the multipart video", video)
+	}
+}
+
+func TestIsMultipartETag(t *testing.T) {
+	cases := map[string]bool{
+		"d41d8cd98f00b204e9800998ecf8427e":    false,
+		"9b2cf535f27731c974343645a3985328-2":  true,
+		"9b2cf535f27731c974343645a3985328-12": true,
+		"9b2cf535f27731c974343645a3985328-":   false,
+		"not-an-etag":                         false,
+	}
+
+	for etag, expected := range cases {
+		if got := IsMultipartETag(etag); got != expected {
+			t.Errorf("IsMultipartETag(%s) == %v, want %v", etag, got, expected)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// Histogram counts the objects and bytes falling in each range of a set of histogram ranges
+type Histogram struct {
+	Number []int
+	Size   []int
+}
+
+// HistogramRangeReport is the machine-readable view of one range of a histogram
+type HistogramRangeReport struct {
+	Range   string `json:"range"`
+	Objects int    `json:"objects"`
+	Bytes   int    `json:"bytes"`
+}
+
+func NewHistogram(ranges []helpers.HistogramRange) *Histogram {
+	return &Histogram{
+		Number: make([]int, len(ranges)),
+		Size:   make([]int, len(ranges)),
+	}
+}
+
+func (h *Histogram) Add(index, size int) {
+	h.Number[index]++
+	h.Size[index] += size
+}
+
+func (h *Histogram) Merge(other *Histogram) {
+	for i := range other.Number {
+		h.Number[i] += other.Number[i]
+		h.Size[i] += other.Size[i]
+	}
+}
+
+func (h *Histogram) Println(ranges []helpers.HistogramRange, displaySettings DisplaySettings, indent string) {
+	maxNumber := 0
+	for _, number := range h.Number {
+		maxNumber = max(maxNumber, number)
+	}
+
+	for i, r := range ranges {
+		fmt.Printf("%v%-12v %v %v objects (%v)\n", indent, r.Label, helpers.FormatHistogramBar(h.Number[i], maxNumber, 30), h.Number[i], helpers.FormatFileSize(h.Size[i], displaySettings.FileSize))
+	}
+}
+
+func (h *Histogram) Report(ranges []helpers.HistogramRange) []HistogramRangeReport {
+	report := []HistogramRangeReport{}
+	for i, r := range ranges {
+		report = append(report, HistogramRangeReport{
+			Range:   r.Label,
+			Objects: h.Number[i],
+			Bytes:   h.Size[i],
+		})
+	}
+	return report
+}
+
+// mergeHistograms merges histograms keyed by storage type
+func mergeHistograms(histograms, other map[string]*Histogram, ranges []helpers.HistogramRange) {
+	for storageType, histogram := range other {
+		if histograms[storageType] == nil {
+			histograms[storageType] = NewHistogram(ranges)
+		}
+		histograms[storageType].Merge(histogram)
+	}
+}
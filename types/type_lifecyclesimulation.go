@@ -0,0 +1,294 @@
+package types
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// LifecycleSimulation is the projected effect of a lifecycle configuration on the current objects of
+// a bucket. The bucket is priced on its own, without the minimum billable size nor the share of the
+// region tiers, so that both costs are comparable.
+type LifecycleSimulation struct {
+	Bucket     string  `json:"bucket"`
+	Region     string  `json:"region"`
+	CostBefore float64 `json:"costBefore"`
+	CostAfter  float64 `json:"costAfter"`
+	// Objects transitioned per target storage type
+	Transitions    map[string]int `json:"transitions"`
+	TransitionCost float64        `json:"transitionCost"`
+	ExpiredObjects int            `json:"expiredObjects"`
+	ExpiredSize    int            `json:"expiredSize"`
+	// Remaining minimum storage duration of the objects expired or transitioned too early
+	EarlyDeletionCost float64  `json:"earlyDeletionCost"`
+	Warnings          []string `json:"warnings"`
+	Errors            []string `json:"errors"`
+}
+
+// SimulateLifecycle applies the enabled rules of a configuration once to the recorded objects of a
+// bucket, as if the lifecycle actions were all run at the given time
+func SimulateLifecycle(bucket *Bucket, configuration *s3types.BucketLifecycleConfiguration, now time.Time, pricing helpers.Pricing) LifecycleSimulation {
+	simulation := LifecycleSimulation{
+		Bucket:      bucket.Name,
+		Region:      bucket.Region,
+		Transitions: map[string]int{},
+		Warnings:    []string{},
+		Errors:      errorStrings(bucket.Errors),
+	}
+
+	rules := []s3types.LifecycleRule{}
+	for i, rule := range configuration.Rules {
+		if rule.Status != s3types.ExpirationStatusEnabled {
+			continue
+		}
+		if rule.NoncurrentVersionExpiration != nil || len(rule.NoncurrentVersionTransitions) > 0 {
+			simulation.Warnings = append(simulation.Warnings, fmt.Sprintf("rule %v: noncurrent version actions are not simulated", helpers.LifecycleRuleName(rule, i)))
+		}
+		if rule.AbortIncompleteMultipartUpload != nil {
+			simulation.Warnings = append(simulation.Warnings, fmt.Sprintf("rule %v: incomplete multipart uploads are not simulated", helpers.LifecycleRuleName(rule, i)))
+		}
+		rules = append(rules, rule)
+	}
+
+	before, after := newStorageUsage(), newStorageUsage()
+	earlyDeletionCost := 0.0
+
+	for _, object := range bucket.Objects {
+		before.add(object)
+
+		ageInDays := int(now.Sub(object.LastModified) / (24 * time.Hour))
+		storageType, expired := lifecycleOutcome(rules, object, ageInDays, now)
+		if expired || storageType != object.StorageType {
+			// The time spent in the current storage type is unknown, the age is its upper bound
+			cost, err := pricing.CalculateEarlyDeletionCost(object.StorageType, bucket.Region, object.Size, ageInDays)
+			if err != nil {
+				simulation.Errors = append(simulation.Errors, err.Error())
+				return simulation
+			}
+			earlyDeletionCost += cost
+		}
+
+		if expired {
+			simulation.ExpiredObjects++
+			simulation.ExpiredSize += object.Size
+			continue
+		}
+
+		if storageType != object.StorageType {
+			simulation.Transitions[storageType]++
+			object.StorageType = storageType
+		}
+		after.add(object)
+	}
+	simulation.EarlyDeletionCost = math.Round(earlyDeletionCost*100) / 100
+
+	var err error
+	if simulation.CostBefore, err = before.cost(bucket.Region, pricing); err != nil {
+		simulation.Errors = append(simulation.Errors, err.Error())
+		return simulation
+	}
+	if simulation.CostAfter, err = after.cost(bucket.Region, pricing); err != nil {
+		simulation.Errors = append(simulation.Errors, err.Error())
+		return simulation
+	}
+
+	transitionCost := 0.0
+	for storageType, number := range simulation.Transitions {
+		cost, err := pricing.CalculateTransitionsCost(storageType, bucket.Region, number)
+		if err != nil {
+			simulation.Errors = append(simulation.Errors, err.Error())
+			return simulation
+		}
+		transitionCost += cost
+	}
+	simulation.TransitionCost = math.Round(transitionCost*100) / 100
+
+	return simulation
+}
+
+// lifecycleOutcome returns the storage type an object ends up in, or whether it is expired. Expiration
+// takes precedence over transitions, and the coldest of the due transitions wins.
+func lifecycleOutcome(rules []s3types.LifecycleRule, object ObjectRecord, ageInDays int, now time.Time) (storageType string, expired bool) {
+	storageType = object.StorageType
+	for _, rule := range rules {
+		if !helpers.LifecycleRuleMatches(rule, object.Key, object.Size, object.Tags) {
+			continue
+		}
+
+		if rule.Expiration != nil && helpers.LifecycleActionDue(rule.Expiration.Days, rule.Expiration.Date, ageInDays, now) {
+			return "", true
+		}
+
+		// Objects under 128 KB are not transitioned unless the rule filters on the object size
+		if object.Size < helpers.SmallObjectSize && !helpers.LifecycleRuleHasSizeFilter(rule) {
+			continue
+		}
+
+		for _, transition := range rule.Transitions {
+			target := string(transition.StorageClass)
+			if helpers.LifecycleActionDue(transition.Days, transition.Date, ageInDays, now) && helpers.CanTransition(storageType, target) {
+				storageType = target
+			}
+		}
+	}
+	return storageType, false
+}
+
+// storageUsage is the size and number of objects per storage type of a simulated bucket
+type storageUsage struct {
+	size   map[string]int
+	number map[string]int
+	// Intelligent-Tiering objects billed the monitoring fee, and the size of the other ones
+	monitored       int
+	unmonitoredSize int
+}
+
+func newStorageUsage() *storageUsage {
+	return &storageUsage{size: map[string]int{}, number: map[string]int{}}
+}
+
+func (u *storageUsage) add(object ObjectRecord) {
+	u.size[object.StorageType] += object.Size
+	u.number[object.StorageType]++
+	if object.StorageType == "INTELLIGENT_TIERING" {
+		if object.Size >= helpers.SmallObjectSize {
+			u.monitored++
+		} else {
+			u.unmonitoredSize += object.Size
+		}
+	}
+}
+
+func (u *storageUsage) cost(region string, pricing helpers.Pricing) (float64, error) {
+	totalCost := 0.0
+	for storageType, size := range u.size {
+		if storageType == "INTELLIGENT_TIERING" {
+			cost, err := pricing.CalculateIntelligentTieringCost(region, size, u.monitored, u.unmonitoredSize, size, pricing.DefaultIntelligentTieringSplit())
+			if err != nil {
+				return 0.0, err
+			}
+			totalCost += cost
+			continue
+		}
+
+		cost, err := pricing.CalculateObjectsCostByStorageType(storageType, region, size, u.number[storageType])
+		if err != nil {
+			return 0.0, err
+		}
+		totalCost += cost
+	}
+	return math.Round(totalCost*100) / 100, nil
+}
+
+func (s LifecycleSimulation) TransitionedObjects() int {
+	total := 0
+	for _, number := range s.Transitions {
+		total += number
+	}
+	return total
+}
+
+func (s LifecycleSimulation) Println(displaySettings DisplaySettings) {
+	fmt.Printf("Name: %v\n", s.Bucket)
+	fmt.Printf("  - Region: %v\n", s.Region)
+	fmt.Printf("  - Storage cost: $%v per month before, $%v per month after\n", s.CostBefore, s.CostAfter)
+
+	transitions := []string{}
+	for _, storageType := range slices.Sorted(maps.Keys(s.Transitions)) {
+		transitions = append(transitions, fmt.Sprintf("%v: %v", storageType, s.Transitions[storageType]))
+	}
+	fmt.Printf("  - Transitions: %v objects %v, $%v one-time\n", s.TransitionedObjects(), transitions, s.TransitionCost)
+	fmt.Printf("  - Expirations: %v objects (%v)\n", s.ExpiredObjects, helpers.FormatFileSize(s.ExpiredSize, displaySettings.FileSize))
+	fmt.Printf("  - Early deletion penalties: $%v one-time\n", s.EarlyDeletionCost)
+
+	for _, warning := range s.Warnings {
+		fmt.Printf("  - Warning: %v\n", warning)
+	}
+
+	if len(s.Errors) > 0 {
+		fmt.Printf("  - Errors (partial results):\n")
+		for _, err := range s.Errors {
+			fmt.Printf("    - %v\n", err)
+		}
+	}
+}
+
+func PrintSimulationTotals(simulations []LifecycleSimulation) {
+	var costBefore, costAfter, transitionCost, earlyDeletionCost float64
+	for _, simulation := range simulations {
+		costBefore += simulation.CostBefore
+		costAfter += simulation.CostAfter
+		transitionCost += simulation.TransitionCost
+		earlyDeletionCost += simulation.EarlyDeletionCost
+	}
+
+	fmt.Printf("Total:\n")
+	fmt.Printf("  - Storage cost: $%v per month before, $%v per month after\n", math.Round(costBefore*100)/100, math.Round(costAfter*100)/100)
+	fmt.Printf("  - One-time cost: $%v of transitions and $%v of early deletion penalties\n", math.Round(transitionCost*100)/100, math.Round(earlyDeletionCost*100)/100)
+}
+
+// SimulationReport is the machine-readable view of a lifecycle simulation
+type SimulationReport struct {
+	StartTime string                `json:"startTime"`
+	EndTime   string                `json:"endTime"`
+	Account   string                `json:"account"`
+	Buckets   []LifecycleSimulation `json:"buckets"`
+	Errors    []string              `json:"errors"`
+}
+
+func NewSimulationReport(startTime, endTime time.Time, account string, simulations []LifecycleSimulation, errors []error, displaySettings DisplaySettings) SimulationReport {
+	return SimulationReport{
+		StartTime: formatDate(startTime, displaySettings),
+		EndTime:   formatDate(endTime, displaySettings),
+		Account:   account,
+		Buckets:   simulations,
+		Errors:    errorStrings(errors),
+	}
+}
+
+func (r SimulationReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes one row per bucket
+func (r SimulationReport) WriteCSV(w io.Writer, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	header := []string{"bucket", "region", "monthly_cost_before", "monthly_cost_after", "transitioned_objects", "transition_cost", "expired_objects", "expired_bytes", "early_deletion_cost"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, simulation := range r.Buckets {
+		row := []string{
+			simulation.Bucket,
+			simulation.Region,
+			strconv.FormatFloat(simulation.CostBefore, 'f', 2, 64),
+			strconv.FormatFloat(simulation.CostAfter, 'f', 2, 64),
+			strconv.Itoa(simulation.TransitionedObjects()),
+			strconv.FormatFloat(simulation.TransitionCost, 'f', 2, 64),
+			strconv.Itoa(simulation.ExpiredObjects),
+			strconv.Itoa(simulation.ExpiredSize),
+			strconv.FormatFloat(simulation.EarlyDeletionCost, 'f', 2, 64),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
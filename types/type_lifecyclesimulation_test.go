@@ -0,0 +1,59 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+func TestSimulateLifecycle(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	now := time.Now()
+
+	bucket := &Bucket{Name: "logs", Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{})}
+	for _, object := range []ObjectRecord{
+		{Key: "logs/old", StorageType: "STANDARD", Size: 100 * gb, LastModified: now.AddDate(0, 0, -100)},
+		{Key: "logs/recent", StorageType: "STANDARD", Size: 100 * gb, LastModified: now.AddDate(0, 0, -10)},
+		{Key: "logs/expired", StorageType: "STANDARD_IA", Size: 100 * gb, LastModified: now.AddDate(0, 0, -400)},
+		{Key: "logs/small", StorageType: "STANDARD", Size: 1024, LastModified: now.AddDate(0, 0, -100)},
+		{Key: "data/old", StorageType: "STANDARD", Size: 100 * gb, LastModified: now.AddDate(0, 0, -100)},
+	} {
+		bucket.AddObject(object)
+		bucket.RecordObject(object)
+	}
+
+	configuration := &s3types.BucketLifecycleConfiguration{
+		Rules: []s3types.LifecycleRule{{
+			Status: s3types.ExpirationStatusEnabled,
+			Filter: &s3types.LifecycleRuleFilter{Prefix: aws.String("logs/")},
+			Transitions: []s3types.Transition{
+				{Days: aws.Int32(30), StorageClass: s3types.TransitionStorageClassStandardIa},
+				{Days: aws.Int32(90), StorageClass: s3types.TransitionStorageClassGlacier},
+			},
+			Expiration: &s3types.LifecycleExpiration{Days: aws.Int32(365)},
+		}},
+	}
+
+	simulation := SimulateLifecycle(bucket, configuration, now, helpers.Pricing{})
+
+	if len(simulation.Errors) > 0 {
+		t.Fatalf("SimulateLifecycle() returned errors: %v", simulation.Errors)
+	}
+	// Only the old log goes to the coldest due storage class, the small one stays in STANDARD
+	if simulation.TransitionedObjects() != 1 || simulation.Transitions["GLACIER"] != 1 {
+		t.Errorf("SimulateLifecycle().Transitions == %v, want 1 to GLACIER", simulation.Transitions)
+	}
+	if simulation.ExpiredObjects != 1 || simulation.ExpiredSize != 100*gb {
+		t.Errorf("SimulateLifecycle() expired %v objects of %v bytes, want 1 of %v", simulation.ExpiredObjects, simulation.ExpiredSize, 100*gb)
+	}
+	// 300 GB of STANDARD and 100 GB of STANDARD_IA, then 200 GB of STANDARD and 100 GB of GLACIER
+	if simulation.CostBefore != 8.15 || simulation.CostAfter != 4.96 {
+		t.Errorf("SimulateLifecycle() costs == %v before and %v after, want 8.15 and 4.96", simulation.CostBefore, simulation.CostAfter)
+	}
+	if simulation.TransitionCost != 0.00 || simulation.EarlyDeletionCost != 0 {
+		t.Errorf("SimulateLifecycle() one-time costs == %v and %v, want 0 and 0", simulation.TransitionCost, simulation.EarlyDeletionCost)
+	}
+}
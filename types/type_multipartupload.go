@@ -0,0 +1,160 @@
+package types
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// MultipartUpload is an upload that was started but neither completed nor aborted, its parts are
+// billed as storage without showing up in the object listing
+type MultipartUpload struct {
+	Key         string
+	UploadID    string
+	StorageType string
+	Initiated   time.Time
+	Parts       int
+	Size        int
+}
+
+type MultipartUploadReport struct {
+	Key         string `json:"key"`
+	UploadID    string `json:"uploadId"`
+	StorageType string `json:"storageType"`
+	Initiated   string `json:"initiated"`
+	AgeInDays   int    `json:"ageInDays"`
+	Parts       int    `json:"parts"`
+	Size        int    `json:"size"`
+}
+
+func (b *Bucket) AddMultipartUpload(upload MultipartUpload) {
+	b.Lock.Lock()
+	b.MultipartUploads = append(b.MultipartUploads, upload)
+	b.Lock.Unlock()
+}
+
+func (b *Bucket) MultipartUploadsSize() int {
+	totalSize := 0
+	for _, upload := range b.MultipartUploads {
+		totalSize += upload.Size
+	}
+	return totalSize
+}
+
+// OldestMultipartUpload returns the upload initiated first, false when there is none
+func (b *Bucket) OldestMultipartUpload() (MultipartUpload, bool) {
+	if len(b.MultipartUploads) == 0 {
+		return MultipartUpload{}, false
+	}
+	return slices.MinFunc(b.MultipartUploads, func(a, b MultipartUpload) int {
+		return a.Initiated.Compare(b.Initiated)
+	}), true
+}
+
+// MultipartUploadsCostByStorageType is the monthly storage cost of the parts of the incomplete
+// uploads, priced in the storage type of each upload. They count in the region usage like the
+// noncurrent versions.
+func (b *Bucket) MultipartUploadsCostByStorageType(pricing helpers.Pricing) (map[string]float64, error) {
+	sizes, numbers := map[string]int{}, map[string]int{}
+	for _, upload := range b.MultipartUploads {
+		sizes[upload.StorageType] += upload.Size
+		numbers[upload.StorageType]++
+	}
+
+	costs := map[string]float64{}
+	for _, storageType := range slices.Sorted(maps.Keys(sizes)) {
+		regionSize := b.ObjectsSize[storageType] + b.NoncurrentObjectsSize[storageType] + sizes[storageType]
+		if b.RegionObjectsSize != nil {
+			regionSize = b.RegionObjectsSize[storageType]
+		}
+
+		cost, err := pricing.CalculateSharedObjectsCost(storageType, b.Region, sizes[storageType], numbers[storageType], regionSize)
+		if err != nil {
+			return costs, err
+		}
+		costs[storageType] = cost
+	}
+	return costs, nil
+}
+
+func (b *Bucket) MultipartUploadsCost(pricing helpers.Pricing) (float64, error) {
+	costs, err := b.MultipartUploadsCostByStorageType(pricing)
+	if err != nil {
+		return 0.0, err
+	}
+
+	totalCost := 0.0
+	for _, cost := range costs {
+		totalCost += cost
+	}
+	return math.Round(totalCost*100) / 100, nil
+}
+
+// HasAbortIncompleteMultipartUploadRule tells if enabled lifecycle rules clean up every incomplete
+// upload of the bucket, a rule only covering the uploads its filter matches. Without uploads, any
+// enabled rule setting AbortIncompleteMultipartUpload is enough.
+func (b *Bucket) HasAbortIncompleteMultipartUploadRule() bool {
+	abortRules := []s3types.LifecycleRule{}
+	for _, rule := range b.LifecycleRules {
+		if rule.Status == s3types.ExpirationStatusEnabled && rule.AbortIncompleteMultipartUpload != nil {
+			abortRules = append(abortRules, rule)
+		}
+	}
+	if len(abortRules) == 0 {
+		return false
+	}
+
+	for _, upload := range b.MultipartUploads {
+		covered := slices.ContainsFunc(abortRules, func(rule s3types.LifecycleRule) bool {
+			return helpers.LifecycleRuleMatches(rule, upload.Key, upload.Size, nil)
+		})
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// MissesAbortIncompleteMultipartUploadRule tells if the multipart uploads were checked and no
+// lifecycle rule cleans them up, false when the lifecycle rules are unknown
+func (b *Bucket) MissesAbortIncompleteMultipartUploadRule() bool {
+	return b.MultipartUploadsScanned && !b.LifecycleUnknown && !b.HasAbortIncompleteMultipartUploadRule()
+}
+
+func (b *Bucket) printMultipartUploads(displaySettings DisplaySettings) {
+	if !b.MultipartUploadsScanned {
+		return
+	}
+	pricing := displaySettings.Pricing
+
+	if oldest, ok := b.OldestMultipartUpload(); ok {
+		cost, err := b.MultipartUploadsCost(pricing)
+		costText := fmt.Sprintf("$%v per month (included above)", cost)
+		if err != nil {
+			costText = fmt.Sprintf("cost unavailable (%v)", err)
+		}
+		fmt.Printf("  - Incomplete multipart uploads: %v uploads (%v), oldest initiated %v days ago, %v\n", len(b.MultipartUploads), helpers.FormatFileSize(b.MultipartUploadsSize(), displaySettings.FileSize), helpers.AgeInDays(oldest.Initiated), costText)
+	}
+
+	if b.MissesAbortIncompleteMultipartUploadRule() {
+		fmt.Printf("  - Warning: no lifecycle rule aborts the incomplete multipart uploads (AbortIncompleteMultipartUpload)\n")
+	}
+}
+
+func (u MultipartUpload) Report(displaySettings DisplaySettings) MultipartUploadReport {
+	return MultipartUploadReport{
+		Key:         u.Key,
+		UploadID:    u.UploadID,
+		StorageType: u.StorageType,
+		Initiated:   formatDate(u.Initiated, displaySettings),
+		AgeInDays:   helpers.AgeInDays(u.Initiated),
+		Parts:       u.Parts,
+		Size:        u.Size,
+	}
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+func TestBucketMultipartUploads(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+
+	bucket := &Bucket{Name: "uploads", Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{}), MultipartUploadsScanned: true}
+	bucket.AddObject(ObjectRecord{Key: "data", StorageType: "STANDARD", Size: 100 * gb, LastModified: time.Now()})
+	bucket.AddMultipartUpload(MultipartUpload{Key: "big", StorageType: "STANDARD", Initiated: time.Now().AddDate(0, 0, -10), Parts: 10, Size: 50 * gb})
+	bucket.AddMultipartUpload(MultipartUpload{Key: "older", StorageType: "STANDARD", Initiated: time.Now().AddDate(0, 0, -40), Parts: 5, Size: 50 * gb})
+
+	oldest, ok := bucket.OldestMultipartUpload()
+	if !ok || oldest.Key != "older" {
+		t.Errorf("OldestMultipartUpload() == %v, want older", oldest.Key)
+	}
+
+	// 100 GB of parts on top of the 100 GB of objects
+	totalCost, err := bucket.TotalCost(helpers.Pricing{})
+	if err != nil {
+		t.Fatalf("TotalCost() returned an error: %s", err)
+	}
+	if totalCost != 4.6 {
+		t.Errorf("TotalCost() == %v, want 4.6", totalCost)
+	}
+
+	if !bucket.MissesAbortIncompleteMultipartUploadRule() {
+		t.Errorf("MissesAbortIncompleteMultipartUploadRule() == false without lifecycle rules")
+	}
+
+	bucket.LifecycleRules = []s3types.LifecycleRule{{
+		Status:                         s3types.ExpirationStatusEnabled,
+		AbortIncompleteMultipartUpload: &s3types.AbortIncompleteMultipartUpload{DaysAfterInitiation: aws.Int32(7)},
+	}}
+	if bucket.MissesAbortIncompleteMultipartUploadRule() {
+		t.Errorf("MissesAbortIncompleteMultipartUploadRule() == true with an enabled rule")
+	}
+
+	// A rule scoped to a prefix leaves the other uploads behind
+	bucket.LifecycleRules = []s3types.LifecycleRule{{
+		Status:                         s3types.ExpirationStatusEnabled,
+		Filter:                         &s3types.LifecycleRuleFilter{Prefix: aws.String("tmp/")},
+		AbortIncompleteMultipartUpload: &s3types.AbortIncompleteMultipartUpload{DaysAfterInitiation: aws.Int32(7)},
+	}}
+	if !bucket.MissesAbortIncompleteMultipartUploadRule() {
+		t.Errorf("MissesAbortIncompleteMultipartUploadRule() == false with a rule scoped to tmp/")
+	}
+
+	bucket.LifecycleRules[0].Filter = &s3types.LifecycleRuleFilter{Prefix: aws.String("")}
+	if bucket.MissesAbortIncompleteMultipartUploadRule() {
+		t.Errorf("MissesAbortIncompleteMultipartUploadRule() == true with a rule matching every key")
+	}
+}
+
+func TestBucketMultipartUploadsUnknownLifecycle(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+
+	// The lifecycle configuration could not be fetched, the uploads are still priced
+	bucket := &Bucket{Name: "uploads", Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{}), MultipartUploadsScanned: true, LifecycleUnknown: true}
+	bucket.AddMultipartUpload(MultipartUpload{Key: "big", StorageType: "STANDARD", Initiated: time.Now().AddDate(0, 0, -10), Parts: 10, Size: 100 * gb})
+
+	cost, err := bucket.MultipartUploadsCost(helpers.Pricing{})
+	if err != nil {
+		t.Fatalf("MultipartUploadsCost() returned an error: %s", err)
+	}
+	if cost != 2.3 {
+		t.Errorf("MultipartUploadsCost() == %v, want 2.3", cost)
+	}
+
+	if bucket.MissesAbortIncompleteMultipartUploadRule() {
+		t.Errorf("MissesAbortIncompleteMultipartUploadRule() == true with unknown lifecycle rules")
+	}
+}
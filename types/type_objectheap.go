@@ -0,0 +1,92 @@
+package types
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// ObjectHeap keeps the limit objects ranking the highest. It is a min-heap on the rank, so the
+// lowest ranked object kept is on top and is the one evicted by a higher ranked object.
+type ObjectHeap struct {
+	Objects []ObjectRecord
+	limit   int
+	// Tells if a ranks lower than b
+	lower func(a, b ObjectRecord) bool
+}
+
+func NewLargestObjectsHeap(limit int) *ObjectHeap {
+	return &ObjectHeap{
+		Objects: []ObjectRecord{},
+		limit:   limit,
+		lower: func(a, b ObjectRecord) bool {
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+			return a.Key > b.Key
+		},
+	}
+}
+
+func NewOldestObjectsHeap(limit int) *ObjectHeap {
+	return &ObjectHeap{
+		Objects: []ObjectRecord{},
+		limit:   limit,
+		lower: func(a, b ObjectRecord) bool {
+			if !a.LastModified.Equal(b.LastModified) {
+				return a.LastModified.After(b.LastModified)
+			}
+			return a.Key > b.Key
+		},
+	}
+}
+
+func (h *ObjectHeap) Len() int           { return len(h.Objects) }
+func (h *ObjectHeap) Less(i, j int) bool { return h.lower(h.Objects[i], h.Objects[j]) }
+func (h *ObjectHeap) Swap(i, j int)      { h.Objects[i], h.Objects[j] = h.Objects[j], h.Objects[i] }
+
+func (h *ObjectHeap) Push(x any) {
+	h.Objects = append(h.Objects, x.(ObjectRecord))
+}
+
+func (h *ObjectHeap) Pop() any {
+	last := h.Objects[len(h.Objects)-1]
+	h.Objects = h.Objects[:len(h.Objects)-1]
+	return last
+}
+
+// Add keeps the object if it ranks higher than the lowest one kept, in O(log limit)
+func (h *ObjectHeap) Add(object ObjectRecord) {
+	if h.limit == 0 {
+		return
+	}
+
+	if len(h.Objects) < h.limit {
+		heap.Push(h, object)
+		return
+	}
+	if h.lower(h.Objects[0], object) {
+		h.Objects[0] = object
+		heap.Fix(h, 0)
+	}
+}
+
+func (h *ObjectHeap) Merge(other *ObjectHeap) {
+	for _, object := range other.Objects {
+		h.Add(object)
+	}
+}
+
+// Sorted returns the objects kept, the highest ranked first
+func (h *ObjectHeap) Sorted() []ObjectRecord {
+	objects := slices.Clone(h.Objects)
+	slices.SortFunc(objects, func(a, b ObjectRecord) int {
+		if h.lower(b, a) {
+			return -1
+		}
+		if h.lower(a, b) {
+			return 1
+		}
+		return 0
+	})
+	return objects
+}
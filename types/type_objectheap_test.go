@@ -0,0 +1,48 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestObjectHeap(t *testing.T) {
+	now := time.Now()
+
+	// Two workers see half of the objects each, the merged heaps keep the overall top 3
+	first, second := NewLargestObjectsHeap(3), NewLargestObjectsHeap(3)
+	oldestFirst, oldestSecond := NewOldestObjectsHeap(3), NewOldestObjectsHeap(3)
+	for i := range 10 {
+		object := ObjectRecord{Key: fmt.Sprintf("object-%d", i), Size: i * 100, LastModified: now.AddDate(0, 0, -i)}
+		if i%2 == 0 {
+			first.Add(object)
+			oldestFirst.Add(object)
+		} else {
+			second.Add(object)
+			oldestSecond.Add(object)
+		}
+	}
+	first.Merge(second)
+	oldestFirst.Merge(oldestSecond)
+
+	expected := []string{"object-9", "object-8", "object-7"}
+	for name, heap := range map[string]*ObjectHeap{"largest": first, "oldest": oldestFirst} {
+		got := heap.Sorted()
+		if len(got) != len(expected) {
+			t.Fatalf("%s Sorted() returned %d objects, want %d", name, len(got), len(expected))
+		}
+		for i, key := range expected {
+			if got[i].Key != key {
+				t.Errorf("%s Sorted()[%d] == %s, want %s", name, i, got[i].Key, key)
+			}
+		}
+	}
+}
+
+func TestObjectHeapDisabled(t *testing.T) {
+	heap := NewLargestObjectsHeap(0)
+	heap.Add(ObjectRecord{Key: "object", Size: 1})
+	if heap.Len() != 0 {
+		t.Errorf("Len() == %d with a limit of 0, want 0", heap.Len())
+	}
+}
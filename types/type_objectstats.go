@@ -0,0 +1,293 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// ObjectStats aggregates the objects of a bucket. It is not safe for concurrent use, each
+// worker fills its own instance which is then merged into the bucket once.
+type ObjectStats struct {
+	StorageTypes           []string
+	MostRecentModifiedDate time.Time
+	ObjectsNumber          map[string]int
+	ObjectsSize            map[string]int
+	// Objects under 128 KB per storage type
+	SmallObjectsNumber map[string]int
+	SmallObjectsSize   map[string]int
+	// Intelligent-Tiering bytes per access tier, inferred from the last modified dates
+	IntelligentTieringSize map[string]int
+	// Object sizes per storage type, over helpers.SizeHistogramRanges
+	SizeHistograms map[string]*Histogram
+	// Object ages in days per storage type, over helpers.AgeHistogramRanges
+	AgeHistograms map[string]*Histogram
+	// Bytes not modified for more than StaleDays per storage type
+	StaleSize map[string]int
+	// Sizes times the days left before the objects reach the minimum storage duration of their
+	// storage type, per storage type
+	MinimumDurationByteDays map[string]int
+	// Ages of the objects of 128 KB and more per top-level prefix and storage type, over
+	// helpers.AgeHistogramRanges, only filled when ScanSettings.Recommendations is set. Smaller
+	// objects are not moved by lifecycle transitions. Past maxPrefixAgeHistograms prefixes, the
+	// new ones are added up under OtherPrefixes.
+	PrefixAgeHistograms map[string]map[string]*Histogram
+	// Objects per prefix down to ScanSettings.PrefixDepth, only filled when the depth is set
+	Prefixes *PrefixNode
+	// The ScanSettings.TopObjects largest and oldest objects
+	LargestObjects *ObjectHeap
+	OldestObjects  *ObjectHeap
+	// Noncurrent versions per storage type and delete markers, only listed when scanning versions.
+	// The other fields only describe the current versions.
+	NoncurrentObjectsNumber      map[string]int
+	NoncurrentObjectsSize        map[string]int
+	NoncurrentSmallObjectsNumber map[string]int
+	NoncurrentSmallObjectsSize   map[string]int
+	DeleteMarkers                int
+	// Every object, only recorded when the scan needs them (lifecycle simulation)
+	Objects []ObjectRecord
+
+	staleDays       int
+	prefixDepth     int
+	prefixDelimiter string
+	prefixAges      bool
+}
+
+// OtherPrefixes is the key of PrefixAgeHistograms holding the prefixes past the limit. The real
+// prefixes are empty or end with the delimiter, it cannot be one of them.
+const OtherPrefixes = "*"
+
+// Top-level prefixes with their own age histograms per bucket, a bucket can have millions of them
+const maxPrefixAgeHistograms = 1000
+
+type ObjectRecord struct {
+	Key          string
+	StorageType  string
+	Size         int
+	LastModified time.Time
+	ETag         string
+	// Only fetched for the objects matched by a lifecycle rule filtering on tags
+	Tags map[string]string
+}
+
+func NewObjectStats(scanSettings ScanSettings) *ObjectStats {
+	staleDays := scanSettings.StaleDays
+	if staleDays == 0 {
+		staleDays = helpers.DefaultStaleDays
+	}
+	prefixDelimiter := scanSettings.PrefixDelimiter
+	if prefixDelimiter == "" {
+		prefixDelimiter = "/"
+	}
+
+	return &ObjectStats{
+		StorageTypes:            []string{},
+		MostRecentModifiedDate:  time.Time{},
+		ObjectsNumber:           map[string]int{},
+		ObjectsSize:             map[string]int{},
+		SmallObjectsNumber:      map[string]int{},
+		SmallObjectsSize:        map[string]int{},
+		IntelligentTieringSize:  map[string]int{},
+		SizeHistograms:          map[string]*Histogram{},
+		AgeHistograms:           map[string]*Histogram{},
+		StaleSize:               map[string]int{},
+		MinimumDurationByteDays: map[string]int{},
+		PrefixAgeHistograms:     map[string]map[string]*Histogram{},
+		Prefixes:                NewPrefixNode(""),
+		LargestObjects:          NewLargestObjectsHeap(scanSettings.TopObjects),
+		OldestObjects:           NewOldestObjectsHeap(scanSettings.TopObjects),
+		Objects:                 []ObjectRecord{},
+
+		NoncurrentObjectsNumber:      map[string]int{},
+		NoncurrentObjectsSize:        map[string]int{},
+		NoncurrentSmallObjectsNumber: map[string]int{},
+		NoncurrentSmallObjectsSize:   map[string]int{},
+
+		staleDays:       staleDays,
+		prefixDepth:     scanSettings.PrefixDepth,
+		prefixDelimiter: prefixDelimiter,
+		prefixAges:      scanSettings.Recommendations,
+	}
+}
+
+// StaleDays is the number of days without modification after which the objects are stale
+func (s *ObjectStats) StaleDays() int {
+	return s.staleDays
+}
+
+func (s *ObjectStats) AddObject(object ObjectRecord) {
+	key, storageType, size, lastModified := object.Key, object.StorageType, object.Size, object.LastModified
+
+	s.ObjectsNumber[storageType]++
+	s.ObjectsSize[storageType] += size
+	if size < helpers.SmallObjectSize {
+		s.SmallObjectsNumber[storageType]++
+		s.SmallObjectsSize[storageType] += size
+	}
+	// Objects under 128 KB are not monitored, they are billed in Frequent Access outside of the split
+	if storageType == "INTELLIGENT_TIERING" && size >= helpers.SmallObjectSize {
+		s.IntelligentTieringSize[helpers.InferIntelligentTieringAccessTier(lastModified)] += size
+	}
+	if lastModified.After(s.MostRecentModifiedDate) {
+		s.MostRecentModifiedDate = lastModified
+	}
+
+	if s.SizeHistograms[storageType] == nil {
+		s.SizeHistograms[storageType] = NewHistogram(helpers.SizeHistogramRanges)
+	}
+	s.SizeHistograms[storageType].Add(helpers.HistogramIndex(helpers.SizeHistogramRanges, size), size)
+
+	if s.AgeHistograms[storageType] == nil {
+		s.AgeHistograms[storageType] = NewHistogram(helpers.AgeHistogramRanges)
+	}
+	s.AgeHistograms[storageType].Add(helpers.HistogramIndex(helpers.AgeHistogramRanges, helpers.AgeInDays(lastModified)), size)
+	if helpers.IsStale(lastModified, s.staleDays) {
+		s.StaleSize[storageType] += size
+	}
+	if remainingDays := helpers.MinimumStorageDays(storageType) - helpers.AgeInDays(lastModified); remainingDays > 0 {
+		s.MinimumDurationByteDays[storageType] += size * remainingDays
+	}
+
+	if s.prefixAges && size >= helpers.SmallObjectSize {
+		histograms := s.prefixAgeHistograms(helpers.TopLevelPrefix(key, s.prefixDelimiter))
+		if histograms[storageType] == nil {
+			histograms[storageType] = NewHistogram(helpers.AgeHistogramRanges)
+		}
+		histograms[storageType].Add(helpers.HistogramIndex(helpers.AgeHistogramRanges, helpers.AgeInDays(lastModified)), size)
+	}
+
+	if s.prefixDepth > 0 {
+		s.Prefixes.Add(helpers.KeyPrefixes(key, s.prefixDepth, s.prefixDelimiter), storageType, size)
+	}
+
+	s.LargestObjects.Add(object)
+	s.OldestObjects.Add(object)
+
+	s.addStorageType(storageType)
+}
+
+func (s *ObjectStats) AddNoncurrentVersion(object ObjectRecord) {
+	s.NoncurrentObjectsNumber[object.StorageType]++
+	s.NoncurrentObjectsSize[object.StorageType] += object.Size
+	if object.Size < helpers.SmallObjectSize {
+		s.NoncurrentSmallObjectsNumber[object.StorageType]++
+		s.NoncurrentSmallObjectsSize[object.StorageType] += object.Size
+	}
+}
+
+func (s *ObjectStats) TotalNoncurrentSize() int {
+	totalSize := 0
+	for _, size := range s.NoncurrentObjectsSize {
+		totalSize += size
+	}
+	return totalSize
+}
+
+func (s *ObjectStats) TotalNoncurrentObjectNumber() int {
+	totalObjectNumber := 0
+	for _, number := range s.NoncurrentObjectsNumber {
+		totalObjectNumber += number
+	}
+	return totalObjectNumber
+}
+
+func (o ObjectRecord) Println(displaySettings DisplaySettings, indent string) {
+	fmt.Printf("%v- %v: %v, %v, last modified %v, ETag %v\n", indent, o.Key, helpers.FormatFileSize(o.Size, displaySettings.FileSize), o.StorageType, o.LastModified.In(displaySettings.Timezone), o.ETag)
+}
+
+// RecordObject keeps the details of an object, on top of the aggregates of AddObject
+func (s *ObjectStats) RecordObject(record ObjectRecord) {
+	s.Objects = append(s.Objects, record)
+}
+
+func (s *ObjectStats) TotalSize() int {
+	totalSize := 0
+	for _, size := range s.ObjectsSize {
+		totalSize += size
+	}
+	return totalSize
+}
+
+func (s *ObjectStats) TotalObjectNumber() int {
+	totalObjectNumber := 0
+	for _, number := range s.ObjectsNumber {
+		totalObjectNumber += number
+	}
+	return totalObjectNumber
+}
+
+func (s *ObjectStats) Merge(other *ObjectStats) {
+	for storageType, number := range other.ObjectsNumber {
+		s.ObjectsNumber[storageType] += number
+	}
+	for storageType, size := range other.ObjectsSize {
+		s.ObjectsSize[storageType] += size
+	}
+	for storageType, number := range other.SmallObjectsNumber {
+		s.SmallObjectsNumber[storageType] += number
+	}
+	for storageType, size := range other.SmallObjectsSize {
+		s.SmallObjectsSize[storageType] += size
+	}
+	for tier, size := range other.IntelligentTieringSize {
+		s.IntelligentTieringSize[tier] += size
+	}
+	if other.MostRecentModifiedDate.After(s.MostRecentModifiedDate) {
+		s.MostRecentModifiedDate = other.MostRecentModifiedDate
+	}
+	mergeHistograms(s.SizeHistograms, other.SizeHistograms, helpers.SizeHistogramRanges)
+	mergeHistograms(s.AgeHistograms, other.AgeHistograms, helpers.AgeHistogramRanges)
+	for storageType, size := range other.StaleSize {
+		s.StaleSize[storageType] += size
+	}
+	for storageType, byteDays := range other.MinimumDurationByteDays {
+		s.MinimumDurationByteDays[storageType] += byteDays
+	}
+	for prefix, histograms := range other.PrefixAgeHistograms {
+		mergeHistograms(s.prefixAgeHistograms(prefix), histograms, helpers.AgeHistogramRanges)
+	}
+	s.Prefixes.Merge(other.Prefixes)
+	s.LargestObjects.Merge(other.LargestObjects)
+	s.OldestObjects.Merge(other.OldestObjects)
+	s.Objects = append(s.Objects, other.Objects...)
+
+	for storageType, number := range other.NoncurrentObjectsNumber {
+		s.NoncurrentObjectsNumber[storageType] += number
+	}
+	for storageType, size := range other.NoncurrentObjectsSize {
+		s.NoncurrentObjectsSize[storageType] += size
+	}
+	for storageType, number := range other.NoncurrentSmallObjectsNumber {
+		s.NoncurrentSmallObjectsNumber[storageType] += number
+	}
+	for storageType, size := range other.NoncurrentSmallObjectsSize {
+		s.NoncurrentSmallObjectsSize[storageType] += size
+	}
+	s.DeleteMarkers += other.DeleteMarkers
+
+	for _, storageType := range other.StorageTypes {
+		s.addStorageType(storageType)
+	}
+}
+
+// prefixAgeHistograms returns the age histograms of a prefix, or the ones of OtherPrefixes once
+// maxPrefixAgeHistograms prefixes are tracked
+func (s *ObjectStats) prefixAgeHistograms(prefix string) map[string]*Histogram {
+	if s.PrefixAgeHistograms[prefix] == nil {
+		if len(s.PrefixAgeHistograms) >= maxPrefixAgeHistograms {
+			prefix = OtherPrefixes
+		}
+		if s.PrefixAgeHistograms[prefix] == nil {
+			s.PrefixAgeHistograms[prefix] = map[string]*Histogram{}
+		}
+	}
+	return s.PrefixAgeHistograms[prefix]
+}
+
+func (s *ObjectStats) addStorageType(storageType string) {
+	if !slices.Contains(s.StorageTypes, storageType) {
+		s.StorageTypes = append(s.StorageTypes, storageType)
+	}
+}
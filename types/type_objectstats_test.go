@@ -0,0 +1,118 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestObjectStatsMerge(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	now := time.Now()
+
+	objects := []ObjectRecord{
+		{Key: "logs/2024/app.log", StorageType: "STANDARD", Size: 10 * gb, LastModified: now.AddDate(0, 0, -200)},
+		{Key: "logs/2025/app.log", StorageType: "STANDARD", Size: 1024, LastModified: now.AddDate(0, 0, -5)},
+		{Key: "backups/db.dump", StorageType: "STANDARD_IA", Size: 50 * gb, LastModified: now.AddDate(0, 0, -10)},
+		{Key: "backups/small", StorageType: "STANDARD_IA", Size: 2048, LastModified: now.AddDate(0, 0, -400)},
+		{Key: "archive/2020.tar", StorageType: "GLACIER", Size: 100 * gb, LastModified: now.AddDate(0, 0, -20)},
+		{Key: "media/video.mp4", StorageType: "INTELLIGENT_TIERING", Size: 5 * gb, LastModified: now.AddDate(0, 0, -60)},
+		{Key: "media/thumb.jpg", StorageType: "INTELLIGENT_TIERING", Size: 4096, LastModified: now},
+		{Key: "root.txt", StorageType: "STANDARD", Size: 1, LastModified: now.AddDate(0, 0, -1000)},
+	}
+	versions := []ObjectRecord{
+		{Key: "logs/2024/app.log", StorageType: "STANDARD", Size: 5 * gb, LastModified: now.AddDate(0, 0, -300)},
+		{Key: "backups/small", StorageType: "STANDARD_IA", Size: 512, LastModified: now.AddDate(0, 0, -500)},
+	}
+
+	// The heaps keep fewer objects than each half holds, so merging has to evict some
+	scanSettings := ScanSettings{TopObjects: 3, PrefixDepth: 2, PrefixDelimiter: "/", Recommendations: true}
+	whole := NewObjectStats(scanSettings)
+	halves := []*ObjectStats{NewObjectStats(scanSettings), NewObjectStats(scanSettings)}
+	for i, object := range objects {
+		whole.AddObject(object)
+		whole.RecordObject(object)
+		halves[i%2].AddObject(object)
+		halves[i%2].RecordObject(object)
+	}
+	for i, version := range versions {
+		whole.AddNoncurrentVersion(version)
+		halves[i%2].AddNoncurrentVersion(version)
+	}
+	whole.DeleteMarkers = 3
+	halves[0].DeleteMarkers, halves[1].DeleteMarkers = 1, 2
+
+	merged := halves[0]
+	merged.Merge(halves[1])
+
+	fields := []struct {
+		name     string
+		got      any
+		expected any
+	}{
+		{name: "ObjectsNumber", got: merged.ObjectsNumber, expected: whole.ObjectsNumber},
+		{name: "ObjectsSize", got: merged.ObjectsSize, expected: whole.ObjectsSize},
+		{name: "SmallObjectsNumber", got: merged.SmallObjectsNumber, expected: whole.SmallObjectsNumber},
+		{name: "SmallObjectsSize", got: merged.SmallObjectsSize, expected: whole.SmallObjectsSize},
+		{name: "IntelligentTieringSize", got: merged.IntelligentTieringSize, expected: whole.IntelligentTieringSize},
+		{name: "SizeHistograms", got: merged.SizeHistograms, expected: whole.SizeHistograms},
+		{name: "AgeHistograms", got: merged.AgeHistograms, expected: whole.AgeHistograms},
+		{name: "StaleSize", got: merged.StaleSize, expected: whole.StaleSize},
+		{name: "MinimumDurationByteDays", got: merged.MinimumDurationByteDays, expected: whole.MinimumDurationByteDays},
+		{name: "PrefixAgeHistograms", got: merged.PrefixAgeHistograms, expected: whole.PrefixAgeHistograms},
+		{name: "Prefixes", got: merged.Prefixes, expected: whole.Prefixes},
+		{name: "LargestObjects", got: merged.LargestObjects.Sorted(), expected: whole.LargestObjects.Sorted()},
+		{name: "OldestObjects", got: merged.OldestObjects.Sorted(), expected: whole.OldestObjects.Sorted()},
+		{name: "NoncurrentObjectsNumber", got: merged.NoncurrentObjectsNumber, expected: whole.NoncurrentObjectsNumber},
+		{name: "NoncurrentObjectsSize", got: merged.NoncurrentObjectsSize, expected: whole.NoncurrentObjectsSize},
+		{name: "NoncurrentSmallObjectsNumber", got: merged.NoncurrentSmallObjectsNumber, expected: whole.NoncurrentSmallObjectsNumber},
+		{name: "NoncurrentSmallObjectsSize", got: merged.NoncurrentSmallObjectsSize, expected: whole.NoncurrentSmallObjectsSize},
+		{name: "DeleteMarkers", got: merged.DeleteMarkers, expected: whole.DeleteMarkers},
+		{name: "MostRecentModifiedDate", got: merged.MostRecentModifiedDate, expected: whole.MostRecentModifiedDate},
+		// The halves add their storage types and objects in a different order
+		{name: "StorageTypes", got: slices.Sorted(slices.Values(merged.StorageTypes)), expected: slices.Sorted(slices.Values(whole.StorageTypes))},
+		{name: "Objects", got: len(merged.Objects), expected: len(whole.Objects)},
+	}
+	for _, field := range fields {
+		if !reflect.DeepEqual(field.got, field.expected) {
+			t.Errorf("Merge() %v == %+v, want %+v", field.name, field.got, field.expected)
+		}
+	}
+
+	// Every field above is compared against the whole stats, make sure they are not trivially empty
+	if len(whole.LargestObjects.Objects) != 3 || len(whole.Prefixes.Children) != 4 || len(whole.IntelligentTieringSize) == 0 || len(whole.MinimumDurationByteDays) == 0 {
+		t.Errorf("the test objects do not fill the heaps, prefixes, Intelligent-Tiering tiers and minimum durations")
+	}
+}
+
+func TestObjectStatsPrefixAgeHistograms(t *testing.T) {
+	const mb = 1024 * 1024
+	object := ObjectRecord{Key: "logs/app.log", StorageType: "STANDARD", Size: mb, LastModified: time.Now()}
+
+	// Only collected for the recommendations
+	stats := NewObjectStats(ScanSettings{})
+	stats.AddObject(object)
+	if len(stats.PrefixAgeHistograms) != 0 {
+		t.Errorf("AddObject() collected %v prefix age histograms without the recommendations", len(stats.PrefixAgeHistograms))
+	}
+
+	// The prefixes past the limit are added up under OtherPrefixes, in both AddObject and Merge
+	stats = NewObjectStats(ScanSettings{Recommendations: true})
+	other := NewObjectStats(ScanSettings{Recommendations: true})
+	for i := range maxPrefixAgeHistograms + 10 {
+		object.Key = fmt.Sprintf("prefix-%v/object", i)
+		stats.AddObject(object)
+		object.Key = fmt.Sprintf("other-%v/object", i)
+		other.AddObject(object)
+	}
+	stats.Merge(other)
+
+	if len(stats.PrefixAgeHistograms) != maxPrefixAgeHistograms+1 {
+		t.Errorf("PrefixAgeHistograms has %v prefixes, want %v", len(stats.PrefixAgeHistograms), maxPrefixAgeHistograms+1)
+	}
+	if others := stats.PrefixAgeHistograms[OtherPrefixes]["STANDARD"]; others == nil || others.Number[0] != 10+maxPrefixAgeHistograms+10 {
+		t.Errorf("PrefixAgeHistograms[%v] == %+v, want the %v objects past the limit", OtherPrefixes, others, 10+maxPrefixAgeHistograms+10)
+	}
+}
@@ -0,0 +1,157 @@
+package types
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// PrefixNode aggregates the objects of a bucket stored under a prefix, including the ones of its
+// children. The root node has an empty prefix and holds the whole bucket.
+type PrefixNode struct {
+	Prefix        string
+	ObjectsNumber map[string]int
+	ObjectsSize   map[string]int
+	Children      map[string]*PrefixNode
+}
+
+// PrefixReport is the machine-readable view of a prefix node
+type PrefixReport struct {
+	Prefix        string             `json:"prefix"`
+	ObjectsNumber map[string]int     `json:"objectsNumber"`
+	ObjectsSize   map[string]int     `json:"objectsSize"`
+	Costs         map[string]float64 `json:"costs"`
+	Children      []PrefixReport     `json:"children,omitempty"`
+}
+
+func NewPrefixNode(prefix string) *PrefixNode {
+	return &PrefixNode{
+		Prefix:        prefix,
+		ObjectsNumber: map[string]int{},
+		ObjectsSize:   map[string]int{},
+		Children:      map[string]*PrefixNode{},
+	}
+}
+
+// Add counts an object in the node and in the descendants matching its prefixes, as returned by
+// helpers.KeyPrefixes
+func (n *PrefixNode) Add(prefixes []string, storageType string, size int) {
+	node := n
+	for {
+		node.ObjectsNumber[storageType]++
+		node.ObjectsSize[storageType] += size
+
+		if len(prefixes) == 0 {
+			return
+		}
+		if node.Children[prefixes[0]] == nil {
+			node.Children[prefixes[0]] = NewPrefixNode(prefixes[0])
+		}
+		node, prefixes = node.Children[prefixes[0]], prefixes[1:]
+	}
+}
+
+func (n *PrefixNode) Merge(other *PrefixNode) {
+	for storageType, number := range other.ObjectsNumber {
+		n.ObjectsNumber[storageType] += number
+	}
+	for storageType, size := range other.ObjectsSize {
+		n.ObjectsSize[storageType] += size
+	}
+	for prefix, child := range other.Children {
+		if n.Children[prefix] == nil {
+			n.Children[prefix] = NewPrefixNode(prefix)
+		}
+		n.Children[prefix].Merge(child)
+	}
+}
+
+func (n *PrefixNode) TotalSize() int {
+	totalSize := 0
+	for _, size := range n.ObjectsSize {
+		totalSize += size
+	}
+	return totalSize
+}
+
+func (n *PrefixNode) TotalObjectNumber() int {
+	totalObjectNumber := 0
+	for _, number := range n.ObjectsNumber {
+		totalObjectNumber += number
+	}
+	return totalObjectNumber
+}
+
+// SortedChildren returns the children from the largest to the smallest
+func (n *PrefixNode) SortedChildren() []*PrefixNode {
+	children := slices.Collect(maps.Values(n.Children))
+	slices.SortFunc(children, func(a, b *PrefixNode) int {
+		return cmp.Or(cmp.Compare(b.TotalSize(), a.TotalSize()), strings.Compare(a.Prefix, b.Prefix))
+	})
+	return children
+}
+
+// Costs splits the storage cost of each storage type of the bucket between its prefixes in
+// proportion to their size, so the minimum billable size, the region tiers and Intelligent-Tiering
+// are accounted like for the bucket
+func (n *PrefixNode) Costs(bucketCosts map[string]float64, bucketSizes map[string]int) map[string]float64 {
+	costs := map[string]float64{}
+	if bucketCosts == nil {
+		return costs
+	}
+
+	for storageType, size := range n.ObjectsSize {
+		if bucketSizes[storageType] == 0 {
+			costs[storageType] = 0
+			continue
+		}
+		costs[storageType] = math.Round(bucketCosts[storageType]*float64(size)/float64(bucketSizes[storageType])*100) / 100
+	}
+	return costs
+}
+
+// Println prints the children of the node as an indented tree, bucketCosts being nil when the
+// bucket cost is unavailable
+func (n *PrefixNode) Println(bucketCosts map[string]float64, bucketSizes map[string]int, displaySettings DisplaySettings, indent string) {
+	for _, child := range n.SortedChildren() {
+		details := []string{}
+		totalCost := 0.0
+		costs := child.Costs(bucketCosts, bucketSizes)
+		for _, storageType := range slices.Sorted(maps.Keys(child.ObjectsSize)) {
+			detail := fmt.Sprintf("%v: %v", storageType, helpers.FormatFileSize(child.ObjectsSize[storageType], displaySettings.FileSize))
+			if bucketCosts != nil {
+				detail += fmt.Sprintf(" $%v", costs[storageType])
+			}
+			details = append(details, detail)
+			totalCost += costs[storageType]
+		}
+
+		cost := "cost unavailable"
+		if bucketCosts != nil {
+			cost = fmt.Sprintf("$%v per month", math.Round(totalCost*100)/100)
+		}
+
+		fmt.Printf("%v- %v %v, %v objects, %v (%v)\n", indent, child.Prefix, helpers.FormatFileSize(child.TotalSize(), displaySettings.FileSize), child.TotalObjectNumber(), cost, strings.Join(details, ", "))
+		child.Println(bucketCosts, bucketSizes, displaySettings, indent+"  ")
+	}
+}
+
+// Report returns the children of the node, from the largest to the smallest
+func (n *PrefixNode) Report(bucketCosts map[string]float64, bucketSizes map[string]int) []PrefixReport {
+	report := []PrefixReport{}
+	for _, child := range n.SortedChildren() {
+		report = append(report, PrefixReport{
+			Prefix:        child.Prefix,
+			ObjectsNumber: child.ObjectsNumber,
+			ObjectsSize:   child.ObjectsSize,
+			Costs:         child.Costs(bucketCosts, bucketSizes),
+			Children:      child.Report(bucketCosts, bucketSizes),
+		})
+	}
+	return report
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+func TestPrefixTree(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+
+	scanSettings := ScanSettings{PrefixDepth: 2, PrefixDelimiter: "/"}
+	first, second := NewObjectStats(scanSettings), NewObjectStats(scanSettings)
+	first.AddObject(ObjectRecord{Key: "team-a/data/1", StorageType: "STANDARD", Size: 10 * gb, LastModified: time.Now()})
+	first.AddObject(ObjectRecord{Key: "team-b/data/1", StorageType: "STANDARD", Size: 30 * gb, LastModified: time.Now()})
+	second.AddObject(ObjectRecord{Key: "team-a/data/2", StorageType: "STANDARD", Size: 30 * gb, LastModified: time.Now()})
+	second.AddObject(ObjectRecord{Key: "team-a/archive", StorageType: "GLACIER", Size: 100 * gb, LastModified: time.Now()})
+	second.AddObject(ObjectRecord{Key: "root", StorageType: "STANDARD", Size: 1 * gb, LastModified: time.Now()})
+
+	bucket := &Bucket{Name: "shared", Region: "us-east-1", ObjectStats: NewObjectStats(scanSettings)}
+	bucket.MergeStats(first)
+	bucket.MergeStats(second)
+
+	children := bucket.Prefixes.SortedChildren()
+	if len(children) != 2 || children[0].Prefix != "team-a/" || children[1].Prefix != "team-b/" {
+		t.Fatalf("SortedChildren() returned %v children, want team-a/ then team-b/", len(children))
+	}
+
+	teamA := children[0]
+	if teamA.TotalObjectNumber() != 3 || teamA.TotalSize() != 140*gb {
+		t.Errorf("team-a/ holds %v objects of %v bytes, want 3 of %v", teamA.TotalObjectNumber(), teamA.TotalSize(), 140*gb)
+	}
+	if data := teamA.Children["team-a/data/"]; data == nil || data.ObjectsSize["STANDARD"] != 40*gb {
+		t.Errorf("team-a/data/ is missing or has the wrong size")
+	}
+
+	costs, err := bucket.CostByStorageType(helpers.Pricing{})
+	if err != nil {
+		t.Fatalf("CostByStorageType() returned an error: %s", err)
+	}
+
+	// 40 of the 71 GB of STANDARD at 0.023, all of the GLACIER at 0.0036
+	got := teamA.Costs(costs, bucket.ObjectsSize)
+	if got["STANDARD"] != 0.92 || got["GLACIER"] != 0.36 {
+		t.Errorf("Costs() == %v, want STANDARD 0.92 and GLACIER 0.36", got)
+	}
+}
@@ -0,0 +1,224 @@
+package types
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// Recommendation is a suggested storage type change with its estimated savings
+type Recommendation struct {
+	Bucket string `json:"bucket"`
+	// Top-level prefix of the objects to move, empty for the root of the bucket or when the
+	// recommendation is not tied to a prefix, OtherPrefixes for the prefixes past the limit
+	Prefix string `json:"prefix"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	// Minimum number of days since the last modification of the objects to move, 0 when the
+	// recommendation does not depend on the age
+	MinimumAge     int     `json:"minimumAge"`
+	Objects        int     `json:"objects"`
+	Size           int     `json:"size"`
+	MonthlySavings float64 `json:"monthlySavings"`
+	// Transition requests needed to apply the recommendation
+	OneTimeCost float64 `json:"oneTimeCost"`
+	Description string  `json:"description"`
+}
+
+// Moves suggested for the objects not modified for at least minimumAge days, assuming they are
+// rarely read. For a given storage type the move with the highest minimumAge applies.
+var ageRecommendations = []struct {
+	from       string
+	to         string
+	minimumAge int
+}{
+	{from: "STANDARD", to: "STANDARD_IA", minimumAge: 30},
+	{from: "STANDARD", to: "GLACIER_IR", minimumAge: 90},
+	{from: "STANDARD_IA", to: "GLACIER_IR", minimumAge: 90},
+}
+
+// Storage types where objects under 128 KB are billed more than their size. GLACIER and DEEP_ARCHIVE
+// objects also carry metadata but are left out, they need a restore before being copied back and the
+// restore fees are not in the pricing catalog.
+var smallObjectsStorageTypes = []string{"STANDARD_IA", "ONEZONE_IA", "GLACIER_IR"}
+
+// Recommendations suggests the moves of the bucket objects that lower the monthly storage cost,
+// ranked by savings. Each storage type is priced as if the bucket was alone in its region.
+func (b *Bucket) Recommendations(pricing helpers.Pricing) ([]Recommendation, error) {
+	recommendations := []Recommendation{}
+
+	for prefix, histograms := range b.PrefixAgeHistograms {
+		for storageType, histogram := range histograms {
+			prefixRecommendations, err := b.ageRecommendations(pricing, prefix, storageType, histogram)
+			if err != nil {
+				return recommendations, err
+			}
+			recommendations = append(recommendations, prefixRecommendations...)
+		}
+	}
+
+	for _, storageType := range smallObjectsStorageTypes {
+		recommendation, ok, err := b.smallObjectsRecommendation(pricing, storageType)
+		if err != nil {
+			return recommendations, err
+		}
+		if ok {
+			recommendations = append(recommendations, recommendation)
+		}
+	}
+
+	sortRecommendations(recommendations)
+	return recommendations, nil
+}
+
+// ageRecommendations groups the age ranges of the objects of a prefix by the move that applies to them
+func (b *Bucket) ageRecommendations(pricing helpers.Pricing, prefix, storageType string, histogram *Histogram) ([]Recommendation, error) {
+	moves := map[int]*Recommendation{}
+	for i := range helpers.AgeHistogramRanges {
+		// Lower bound of the age range
+		age := 0
+		if i > 0 {
+			age = helpers.AgeHistogramRanges[i-1].UpTo
+		}
+
+		for _, move := range ageRecommendations {
+			if move.from != storageType || move.minimumAge > age || !pricing.IsStorageClassAvailable(move.to, b.Region) {
+				continue
+			}
+			if moves[i] == nil || moves[i].MinimumAge < move.minimumAge {
+				moves[i] = &Recommendation{Bucket: b.Name, Prefix: prefix, From: move.from, To: move.to, MinimumAge: move.minimumAge}
+			}
+		}
+	}
+
+	// Merge the ranges sharing the same move
+	grouped := map[int]*Recommendation{}
+	for i, move := range moves {
+		if grouped[move.MinimumAge] == nil {
+			grouped[move.MinimumAge] = move
+		}
+		grouped[move.MinimumAge].Objects += histogram.Number[i]
+		grouped[move.MinimumAge].Size += histogram.Size[i]
+	}
+
+	recommendations := []Recommendation{}
+	for _, minimumAge := range slices.Sorted(maps.Keys(grouped)) {
+		recommendation := *grouped[minimumAge]
+		if recommendation.Objects == 0 {
+			continue
+		}
+
+		if err := b.priceRecommendation(pricing, &recommendation); err != nil {
+			return recommendations, err
+		}
+		if recommendation.MonthlySavings <= 0 {
+			continue
+		}
+
+		location := "prefix " + prefix
+		switch prefix {
+		case "":
+			location = "objects at the root"
+		case OtherPrefixes:
+			location = "the other prefixes"
+		}
+		ageLabel := fmt.Sprintf(">%v days old", minimumAge)
+		if older := nextMinimumAge(storageType, minimumAge); older != 0 {
+			ageLabel = fmt.Sprintf("%v-%v days old", minimumAge, older)
+		}
+		recommendation.Description = fmt.Sprintf("%v in %v %v → %v saves $%v/mo ($%v one-time)", location, recommendation.From, ageLabel, recommendation.To, recommendation.MonthlySavings, recommendation.OneTimeCost)
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations, nil
+}
+
+// nextMinimumAge returns the minimum age of the next move of a storage type, 0 when there is none
+func nextMinimumAge(storageType string, minimumAge int) int {
+	next := 0
+	for _, move := range ageRecommendations {
+		if move.from == storageType && move.minimumAge > minimumAge && (next == 0 || move.minimumAge < next) {
+			next = move.minimumAge
+		}
+	}
+	return next
+}
+
+// smallObjectsRecommendation suggests moving the objects under 128 KB of a storage type back to
+// STANDARD when their minimum billable size makes them more expensive there
+func (b *Bucket) smallObjectsRecommendation(pricing helpers.Pricing, storageType string) (Recommendation, bool, error) {
+	number, size := b.SmallObjectsNumber[storageType], b.SmallObjectsSize[storageType]
+	if number == 0 {
+		return Recommendation{}, false, nil
+	}
+
+	recommendation := Recommendation{
+		Bucket:  b.Name,
+		From:    storageType,
+		To:      "STANDARD",
+		Objects: number,
+		Size:    size,
+	}
+	if err := b.priceRecommendation(pricing, &recommendation); err != nil {
+		return recommendation, false, err
+	}
+	if recommendation.MonthlySavings <= 0 {
+		return recommendation, false, nil
+	}
+
+	recommendation.Description = fmt.Sprintf("%v objects <128 KB in %v cost more than STANDARD, moving them saves $%v/mo ($%v one-time)", helpers.FormatCount(number), storageType, recommendation.MonthlySavings, recommendation.OneTimeCost)
+	return recommendation, true, nil
+}
+
+// priceRecommendation sets the monthly savings and one-time cost of moving the objects, including
+// the minimum billable size and archive metadata of both storage types
+func (b *Bucket) priceRecommendation(pricing helpers.Pricing, recommendation *Recommendation) error {
+	smallNumber, smallSize := 0, 0
+	if recommendation.MinimumAge == 0 {
+		// Only small objects are moved, age-based moves only hold objects of 128 KB and more
+		smallNumber, smallSize = recommendation.Objects, recommendation.Size
+	}
+
+	current, err := pricing.CalculateStorageCost(recommendation.From, b.Region, recommendation.Size, recommendation.Objects, smallNumber, smallSize, recommendation.Size)
+	if err != nil {
+		return err
+	}
+	target, err := pricing.CalculateStorageCost(recommendation.To, b.Region, recommendation.Size, recommendation.Objects, smallNumber, smallSize, recommendation.Size)
+	if err != nil {
+		return err
+	}
+	recommendation.MonthlySavings = math.Round((current.Cost-target.Cost)*100) / 100
+
+	recommendation.OneTimeCost, err = pricing.CalculateTransitionsCost(recommendation.To, b.Region, recommendation.Objects)
+	return err
+}
+
+// AccountRecommendations ranks the recommendations of every bucket, the buckets that cannot be
+// priced are left out
+func AccountRecommendations(buckets []*Bucket, pricing helpers.Pricing) []Recommendation {
+	recommendations := []Recommendation{}
+	for _, bucket := range buckets {
+		bucketRecommendations, err := bucket.Recommendations(pricing)
+		if err != nil {
+			continue
+		}
+		recommendations = append(recommendations, bucketRecommendations...)
+	}
+
+	sortRecommendations(recommendations)
+	return recommendations
+}
+
+func sortRecommendations(recommendations []Recommendation) {
+	slices.SortFunc(recommendations, func(a, b Recommendation) int {
+		return cmp.Or(
+			cmp.Compare(b.MonthlySavings, a.MonthlySavings),
+			cmp.Compare(a.Bucket, b.Bucket),
+			cmp.Compare(a.Prefix, b.Prefix),
+			cmp.Compare(a.From, b.From),
+		)
+	})
+}
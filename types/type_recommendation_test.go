@@ -0,0 +1,54 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+func TestBucketRecommendations(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	now := time.Now()
+
+	bucket := &Bucket{Name: "logs", Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{Recommendations: true})}
+	bucket.AddObject(ObjectRecord{Key: "logs/old", StorageType: "STANDARD", Size: 100 * gb, LastModified: now.AddDate(0, 0, -200)})
+	bucket.AddObject(ObjectRecord{Key: "logs/recent", StorageType: "STANDARD", Size: 100 * gb, LastModified: now.AddDate(0, 0, -40)})
+	bucket.AddObject(ObjectRecord{Key: "logs/new", StorageType: "STANDARD", Size: 100 * gb, LastModified: now})
+	// 1,000,000 objects of 1 KB in STANDARD_IA, and as many in DEEP_ARCHIVE which are never moved back
+	// even if their metadata costs more than STANDARD
+	for _, storageType := range []string{"STANDARD_IA", "DEEP_ARCHIVE"} {
+		bucket.StorageTypes = append(bucket.StorageTypes, storageType)
+		bucket.ObjectsNumber[storageType] = 1000000
+		bucket.ObjectsSize[storageType] = 1000000 * 1024
+		bucket.SmallObjectsNumber[storageType] = 1000000
+		bucket.SmallObjectsSize[storageType] = 1000000 * 1024
+	}
+
+	recommendations, err := bucket.Recommendations(helpers.Pricing{})
+	if err != nil {
+		t.Fatalf("Recommendations() returned an error: %s", err)
+	}
+
+	// 100 GB from 0.023 to 0.004, 100 GB from 0.023 to 0.0125, and 1,000,000 objects billed
+	// 128 KB in STANDARD_IA instead of 1 KB in STANDARD
+	expected := []struct {
+		prefix  string
+		from    string
+		to      string
+		savings float64
+	}{
+		{prefix: "logs/", from: "STANDARD", to: "GLACIER_IR", savings: 1.9},
+		{prefix: "", from: "STANDARD_IA", to: "STANDARD", savings: 1.51},
+		{prefix: "logs/", from: "STANDARD", to: "STANDARD_IA", savings: 1.05},
+	}
+	if len(recommendations) != len(expected) {
+		t.Fatalf("Recommendations() == %+v, want %v recommendations", recommendations, len(expected))
+	}
+	for i, e := range expected {
+		got := recommendations[i]
+		if got.Prefix != e.prefix || got.From != e.from || got.To != e.to || got.MonthlySavings != e.savings {
+			t.Errorf("Recommendations()[%d] == %+v, want %+v", i, got, e)
+		}
+	}
+}
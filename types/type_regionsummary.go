@@ -0,0 +1,168 @@
+package types
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// RegionSummary holds the buckets of a region along with their combined objects and bytes
+type RegionSummary struct {
+	Region  string
+	Buckets []*Bucket
+
+	// Objects and bytes per storage type of every bucket, the only totals printed per region
+	ObjectsNumber map[string]int
+	ObjectsSize   map[string]int
+}
+
+func newRegionSummary(region string) *RegionSummary {
+	return &RegionSummary{
+		Region:        region,
+		Buckets:       []*Bucket{},
+		ObjectsNumber: map[string]int{},
+		ObjectsSize:   map[string]int{},
+	}
+}
+
+// add counts the objects and bytes of buckets without merging the rest of their stats
+func (r *RegionSummary) add(buckets []*Bucket, objectsNumber, objectsSize map[string]int) {
+	r.Buckets = append(r.Buckets, buckets...)
+	for storageType, number := range objectsNumber {
+		r.ObjectsNumber[storageType] += number
+	}
+	for storageType, size := range objectsSize {
+		r.ObjectsSize[storageType] += size
+	}
+}
+
+// GroupByRegion splits the buckets by region, sorted by region name
+func GroupByRegion(buckets []*Bucket) []*RegionSummary {
+	summaries := map[string]*RegionSummary{}
+	for _, bucket := range buckets {
+		summary, ok := summaries[bucket.Region]
+		if !ok {
+			summary = newRegionSummary(bucket.Region)
+			summaries[bucket.Region] = summary
+		}
+
+		summary.add([]*Bucket{bucket}, bucket.ObjectsNumber, bucket.ObjectsSize)
+	}
+
+	result := []*RegionSummary{}
+	for _, summary := range summaries {
+		result = append(result, summary)
+	}
+	slices.SortFunc(result, func(a, b *RegionSummary) int {
+		return strings.Compare(a.Region, b.Region)
+	})
+
+	return result
+}
+
+// ShareRegionUsage makes every bucket pay its share of the tiered rates applied to its region usage,
+// as AWS aggregates the usage of all the buckets of an account in a region. The noncurrent versions
+// and the parts of the incomplete multipart uploads are billed as storage too.
+func ShareRegionUsage(buckets []*Bucket) {
+	regionSizes := map[string]map[string]int{}
+	for _, bucket := range buckets {
+		if regionSizes[bucket.Region] == nil {
+			regionSizes[bucket.Region] = map[string]int{}
+		}
+		for storageType, size := range bucket.ObjectsSize {
+			regionSizes[bucket.Region][storageType] += size
+		}
+		for storageType, size := range bucket.NoncurrentObjectsSize {
+			regionSizes[bucket.Region][storageType] += size
+		}
+		for _, upload := range bucket.MultipartUploads {
+			regionSizes[bucket.Region][upload.StorageType] += upload.Size
+		}
+	}
+
+	for _, bucket := range buckets {
+		bucket.RegionObjectsSize = regionSizes[bucket.Region]
+	}
+}
+
+func (r *RegionSummary) Println(displaySettings DisplaySettings) {
+	fmt.Printf("Region: %v (%v buckets)\n", r.Region, len(r.Buckets))
+	for _, bucket := range r.Buckets {
+		bucket.Println(displaySettings)
+	}
+
+	fmt.Printf("Total for region %v:\n", r.Region)
+	printTotals(os.Stdout, r, displaySettings)
+}
+
+// PrintGrandTotal prints the totals across every region
+func PrintGrandTotal(summaries []*RegionSummary, displaySettings DisplaySettings) {
+	total := newRegionSummary("")
+	for _, summary := range summaries {
+		total.add(summary.Buckets, summary.ObjectsNumber, summary.ObjectsSize)
+	}
+
+	fmt.Printf("Grand total (%v buckets in %v regions):\n", len(total.Buckets), len(summaries))
+	printTotals(os.Stdout, total, displaySettings)
+}
+
+// printTotals writes the objects, bytes and costs of the buckets of a summary
+func printTotals(w io.Writer, summary *RegionSummary, displaySettings DisplaySettings) {
+	pricing := displaySettings.Pricing
+	buckets := summary.Buckets
+
+	objectsNumber, totalSize := 0, 0
+	for _, number := range summary.ObjectsNumber {
+		objectsNumber += number
+	}
+	for _, size := range summary.ObjectsSize {
+		totalSize += size
+	}
+	fmt.Fprintf(w, "  - Number of files: %v\n", objectsNumber)
+	fmt.Fprintf(w, "  - Total size: %v\n", helpers.FormatFileSize(totalSize, displaySettings.FileSize))
+
+	for _, storageType := range slices.Sorted(maps.Keys(summary.ObjectsSize)) {
+		fmt.Fprintf(w, "    - %v: %v\n", storageType, helpers.FormatFileSize(summary.ObjectsSize[storageType], displaySettings.FileSize))
+	}
+
+	totalCost := 0.0
+	missingCosts := 0
+	for _, bucket := range buckets {
+		cost, err := bucket.TotalCost(pricing)
+		if err != nil {
+			missingCosts++
+			continue
+		}
+		totalCost += cost
+	}
+
+	if missingCosts > 0 {
+		fmt.Fprintf(w, "  - Cost: $%.2f per month (only for storage, excluding %v buckets whose cost is unavailable)\n", totalCost, missingCosts)
+	} else {
+		fmt.Fprintf(w, "  - Cost: $%.2f per month (only for storage)\n", totalCost)
+	}
+
+	requestsCost := 0.0
+	withRequests := 0
+	for _, bucket := range buckets {
+		if len(bucket.Requests) == 0 {
+			continue
+		}
+
+		cost, err := bucket.TotalRequestsCost(pricing)
+		if err != nil {
+			continue
+		}
+		requestsCost += cost
+		withRequests++
+	}
+
+	if withRequests > 0 {
+		fmt.Fprintf(w, "  - Requests cost: $%.2f per month (%v buckets with requests)\n", requestsCost, withRequests)
+	}
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+func TestGroupByRegion(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+
+	objects := map[string][]ObjectRecord{
+		"website": {{Key: "index.html", StorageType: "STANDARD", Size: 10 * gb}},
+		"logs": {
+			{Key: "2024/app.log", StorageType: "STANDARD", Size: 100 * gb},
+			{Key: "2023/app.log", StorageType: "GLACIER", Size: 1000 * gb},
+		},
+		"backups": {{Key: "db.dump", StorageType: "STANDARD", Size: 50 * gb}},
+	}
+	buckets := []*Bucket{
+		{Name: "website", Region: "us-west-2", ObjectStats: NewObjectStats(ScanSettings{})},
+		{Name: "logs", Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{})},
+		{Name: "backups", Region: "us-east-1", ObjectStats: NewObjectStats(ScanSettings{})},
+	}
+	for _, bucket := range buckets {
+		for _, object := range objects[bucket.Name] {
+			object.LastModified = time.Now()
+			bucket.AddObject(object)
+		}
+	}
+
+	cases := []struct {
+		region   string
+		buckets  []string
+		expected string
+	}{
+		{
+			region:  "us-east-1",
+			buckets: []string{"logs", "backups"},
+			expected: "  - Number of files: 3\n" +
+				"  - Total size: 1150.00 GB\n" +
+				"    - GLACIER: 1000.00 GB\n" +
+				"    - STANDARD: 150.00 GB\n" +
+				"  - Cost: $7.05 per month (only for storage)\n",
+		},
+		{
+			region:  "us-west-2",
+			buckets: []string{"website"},
+			expected: "  - Number of files: 1\n" +
+				"  - Total size: 10.00 GB\n" +
+				"    - STANDARD: 10.00 GB\n" +
+				"  - Cost: $0.23 per month (only for storage)\n",
+		},
+	}
+
+	summaries := GroupByRegion(buckets)
+	if len(summaries) != len(cases) {
+		t.Fatalf("GroupByRegion() returned %v regions, want %v", len(summaries), len(cases))
+	}
+
+	displaySettings := DisplaySettings{FileSize: helpers.GB}
+	for i, c := range cases {
+		summary := summaries[i]
+		if summary.Region != c.region {
+			t.Errorf("GroupByRegion()[%v].Region == %v, want %v", i, summary.Region, c.region)
+		}
+
+		names := []string{}
+		for _, bucket := range summary.Buckets {
+			names = append(names, bucket.Name)
+		}
+		if !slices.Equal(names, c.buckets) {
+			t.Errorf("GroupByRegion()[%v].Buckets == %v, want %v", i, names, c.buckets)
+		}
+
+		output := &strings.Builder{}
+		printTotals(output, summary, displaySettings)
+		if output.String() != c.expected {
+			t.Errorf("printTotals(%v) ==\n%v\nwant\n%v", c.region, output.String(), c.expected)
+		}
+	}
+}
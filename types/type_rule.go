@@ -0,0 +1,224 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// Rule severities, from the least to the most severe
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+var severityRanks = map[string]int{SeverityLow: 0, SeverityMedium: 1, SeverityHigh: 2, SeverityCritical: 3}
+
+// Rule is a compliance control evaluated against a bucket and its fetched configuration
+type Rule interface {
+	ID() string
+	Severity() string
+	Description() string
+	Remediation() string
+	// Evaluate returns a message per violation, evaluated being false when the settings needed by
+	// the rule could not be fetched. findings are the Bucket.Findings of the bucket, computed once
+	// for every rule.
+	Evaluate(bucket *Bucket, findings []Finding) (violations []string, evaluated bool)
+}
+
+// findingRule is violated when a check of Bucket.Findings does not pass
+type findingRule struct {
+	id          string
+	severity    string
+	description string
+	remediation string
+	checks      []string
+	// Also accept the warnings, e.g. a wildcard principal limited by conditions
+	allowWarn bool
+}
+
+func (r findingRule) ID() string          { return r.id }
+func (r findingRule) Severity() string    { return r.severity }
+func (r findingRule) Description() string { return r.description }
+func (r findingRule) Remediation() string { return r.remediation }
+
+func (r findingRule) Evaluate(bucket *Bucket, findings []Finding) ([]string, bool) {
+	violations := []string{}
+	evaluated := false
+	for _, finding := range findings {
+		if !slices.Contains(r.checks, finding.Check) {
+			continue
+		}
+		evaluated = true
+
+		if finding.Status == FindingFail || (finding.Status == FindingWarn && !r.allowWarn) {
+			message := finding.Message
+			if finding.Sid != "" {
+				message += fmt.Sprintf(" (statement %v)", finding.Sid)
+			}
+			violations = append(violations, message)
+		}
+	}
+	return violations, evaluated
+}
+
+// newFindingRule builds a rule defined in a profile, checking its severity and the checks it uses
+func newFindingRule(definition helpers.RuleDefinition) (findingRule, error) {
+	if definition.ID == "" {
+		return findingRule{}, fmt.Errorf("rule without id")
+	}
+	if _, ok := severityRanks[definition.Severity]; !ok {
+		return findingRule{}, fmt.Errorf("rule %q has an unknown severity %q", definition.ID, definition.Severity)
+	}
+	if len(definition.Checks) == 0 {
+		return findingRule{}, fmt.Errorf("rule %q has no checks", definition.ID)
+	}
+	for _, check := range definition.Checks {
+		if !slices.Contains(findingChecks, check) {
+			return findingRule{}, fmt.Errorf("rule %q uses an unknown check %q", definition.ID, check)
+		}
+	}
+
+	return findingRule{
+		id:          definition.ID,
+		severity:    definition.Severity,
+		description: definition.Description,
+		remediation: definition.Remediation,
+		checks:      definition.Checks,
+		allowWarn:   definition.AllowWarn,
+	}, nil
+}
+
+// Built-in rule pack, the CIS AWS Foundations Benchmark v3.0 S3 controls and the S3 controls of the
+// AWS Foundational Security Best Practices that can be checked from the bucket configuration. The
+// rules without an equivalent control are named POLICY.n.
+var rules = []Rule{
+	findingRule{
+		id:          "CIS-2.1.1",
+		severity:    SeverityMedium,
+		description: "Ensure S3 Bucket Policy is set to deny HTTP requests",
+		remediation: "Add a Deny statement of s3:* for every principal with the condition {\"Bool\": {\"aws:SecureTransport\": \"false\"}}",
+		checks:      []string{"policy-secure-transport"},
+	},
+	findingRule{
+		id:          "CIS-2.1.2",
+		severity:    SeverityLow,
+		description: "Ensure MFA Delete is enabled on S3 buckets",
+		remediation: "Enable MFA delete with the root account: aws s3api put-bucket-versioning --versioning-configuration Status=Enabled,MFADelete=Enabled --mfa 'arn-of-mfa-device code'",
+		checks:      []string{"mfa-delete"},
+	},
+	findingRule{
+		id:          "CIS-2.1.4",
+		severity:    SeverityHigh,
+		description: "Ensure that S3 Buckets are configured with 'Block public access (bucket settings)'",
+		remediation: "Enable the four settings of the bucket Public Access Block: aws s3api put-public-access-block --public-access-block-configuration BlockPublicAcls=true,IgnorePublicAcls=true,BlockPublicPolicy=true,RestrictPublicBuckets=true",
+		checks:      []string{"public-access-block"},
+	},
+	findingRule{
+		id:          "S3.2",
+		severity:    SeverityCritical,
+		description: "S3 buckets should prohibit public access through their policy and ACLs",
+		remediation: "Remove the statements allowing \"*\" without restricting conditions and the ACL grants to AllUsers or AuthenticatedUsers",
+		checks:      []string{"policy", "policy-wildcard-principal", "acl"},
+		allowWarn:   true,
+	},
+	findingRule{
+		id:          "S3.4",
+		severity:    SeverityMedium,
+		description: "S3 buckets should have server-side encryption enabled",
+		remediation: "Configure a default encryption (SSE-S3 or SSE-KMS): aws s3api put-bucket-encryption",
+		checks:      []string{"encryption"},
+	},
+	findingRule{
+		id:          "S3.6",
+		severity:    SeverityHigh,
+		description: "S3 bucket policies should restrict access to other AWS accounts",
+		remediation: "Remove the principals of the untrusted accounts or add them to --trusted-accounts if they are expected",
+		checks:      []string{"policy-cross-account"},
+	},
+	findingRule{
+		id:          "POLICY.1",
+		severity:    SeverityHigh,
+		description: "S3 bucket policies should not allow every S3 action",
+		remediation: "List the actions needed by each statement instead of s3:*, * or NotAction",
+		checks:      []string{"policy-broad-actions"},
+	},
+	findingRule{
+		id:          "S3.9",
+		severity:    SeverityMedium,
+		description: "S3 buckets should have server access logging enabled",
+		remediation: "Enable server access logging to a dedicated bucket: aws s3api put-bucket-logging",
+		checks:      []string{"logging"},
+	},
+	findingRule{
+		id:          "S3.10",
+		severity:    SeverityMedium,
+		description: "S3 buckets with versioning enabled should have lifecycle configurations",
+		remediation: "Add a lifecycle rule expiring or transitioning the noncurrent versions (NoncurrentVersionExpiration)",
+		checks:      []string{"versioning-lifecycle"},
+	},
+	findingRule{
+		id:          "S3.12",
+		severity:    SeverityMedium,
+		description: "ACLs should not be used to manage user access to S3 buckets",
+		remediation: "Set the object ownership to BucketOwnerEnforced: aws s3api put-bucket-ownership-controls",
+		checks:      []string{"ownership"},
+	},
+	findingRule{
+		id:          "S3.13",
+		severity:    SeverityLow,
+		description: "S3 buckets should have lifecycle configurations",
+		remediation: "Add a lifecycle configuration transitioning or expiring the objects: aws s3api put-bucket-lifecycle-configuration",
+		checks:      []string{"lifecycle"},
+	},
+	findingRule{
+		id:          "S3.14",
+		severity:    SeverityLow,
+		description: "S3 buckets should have versioning enabled",
+		remediation: "Enable versioning: aws s3api put-bucket-versioning --versioning-configuration Status=Enabled",
+		checks:      []string{"versioning"},
+	},
+	findingRule{
+		id:          "S3.15",
+		severity:    SeverityMedium,
+		description: "S3 buckets should have Object Lock enabled",
+		remediation: "Enable Object Lock with a default retention: aws s3api put-object-lock-configuration",
+		checks:      []string{"object-lock"},
+	},
+}
+
+// Rules returns the built-in rules and the rules defined by the profile that the profile enables,
+// from the most to the least severe. The profile cannot name an unknown rule.
+func Rules(profile helpers.RuleProfile) ([]Rule, error) {
+	available := slices.Clone(rules)
+	for _, definition := range profile.Rules {
+		rule, err := newFindingRule(definition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule profile: %w", err)
+		}
+		if slices.ContainsFunc(available, func(other Rule) bool { return other.ID() == rule.ID() }) {
+			return nil, fmt.Errorf("invalid rule profile: rule %q is already defined", rule.ID())
+		}
+		available = append(available, rule)
+	}
+
+	for _, id := range profile.RuleIDs() {
+		if !slices.ContainsFunc(available, func(rule Rule) bool { return rule.ID() == id }) {
+			return nil, fmt.Errorf("invalid rule profile: unknown rule %q", id)
+		}
+	}
+
+	enabled := []Rule{}
+	for _, rule := range available {
+		if profile.IsEnabled(rule.ID()) {
+			enabled = append(enabled, rule)
+		}
+	}
+	slices.SortStableFunc(enabled, func(a, b Rule) int {
+		return severityRanks[b.Severity()] - severityRanks[a.Severity()]
+	})
+	return enabled, nil
+}
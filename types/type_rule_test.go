@@ -0,0 +1,160 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// namingRule is a custom rule requiring a bucket name prefix, like the ones written in Go by the users
+type namingRule struct{}
+
+func (namingRule) ID() string          { return "CUSTOM.1" }
+func (namingRule) Severity() string    { return SeverityLow }
+func (namingRule) Description() string { return "Bucket names should start with the company prefix" }
+func (namingRule) Remediation() string { return "Recreate the bucket with the prefix" }
+func (namingRule) Evaluate(bucket *Bucket, findings []Finding) ([]string, bool) {
+	if len(bucket.Name) < 5 || bucket.Name[:5] != "acme-" {
+		return []string{"name without the acme- prefix"}, true
+	}
+	return nil, true
+}
+
+func TestRules(t *testing.T) {
+	rules, err := Rules(helpers.RuleProfile{Enabled: []string{"S3.13", "CIS-2.1.4", "S3.2", "S3.4"}, Disabled: []string{"S3.4"}})
+	if err != nil {
+		t.Fatalf("Rules() returned an error: %s", err)
+	}
+
+	// From the most to the least severe
+	expected := []string{"S3.2", "CIS-2.1.4", "S3.13"}
+	if len(rules) != len(expected) {
+		t.Fatalf("Rules() returned %v rules, want %v", len(rules), len(expected))
+	}
+	for i, rule := range rules {
+		if rule.ID() != expected[i] {
+			t.Errorf("Rules()[%v] == %v, want %v", i, rule.ID(), expected[i])
+		}
+	}
+
+	if _, err := Rules(helpers.RuleProfile{Disabled: []string{"S3.99"}}); err == nil {
+		t.Errorf("Rules() accepted an unknown rule")
+	}
+
+	// A rule defined by the profile is enabled and sorted like the built-in ones
+	team := helpers.RuleDefinition{ID: "TEAM.1", Severity: SeverityCritical, Description: "Buckets should be encrypted and logged", Checks: []string{"encryption", "logging"}}
+	rules, err = Rules(helpers.RuleProfile{Enabled: []string{"S3.13", "TEAM.1"}, Rules: []helpers.RuleDefinition{team}})
+	if err != nil {
+		t.Fatalf("Rules() returned an error: %s", err)
+	}
+	if len(rules) != 2 || rules[0].ID() != "TEAM.1" || rules[1].ID() != "S3.13" {
+		t.Errorf("Rules() with a profile rule == %v, want TEAM.1 and S3.13", rules)
+	}
+
+	invalid := map[string]helpers.RuleDefinition{
+		"duplicate": {ID: "S3.4", Severity: SeverityLow, Checks: []string{"encryption"}},
+		"severity":  {ID: "TEAM.2", Severity: "urgent", Checks: []string{"encryption"}},
+		"no checks": {ID: "TEAM.2", Severity: SeverityLow},
+		"check":     {ID: "TEAM.2", Severity: SeverityLow, Checks: []string{"encrypted"}},
+	}
+	for name, definition := range invalid {
+		if _, err := Rules(helpers.RuleProfile{Rules: []helpers.RuleDefinition{definition}}); err == nil {
+			t.Errorf("Rules() accepted a profile rule with an invalid %v", name)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	public := &Bucket{Name: "website", Region: "us-east-1", Configuration: &BucketConfiguration{
+		Policy: aws.String(`{"Statement": [{"Sid": "PublicRead", "Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "*"}]}`),
+		Grants: []s3types.Grant{{
+			Grantee:    &s3types.Grantee{Type: s3types.TypeGroup, URI: aws.String(allUsersURI)},
+			Permission: s3types.PermissionRead,
+		}},
+		// The logging settings could not be fetched
+		Missing: []string{"logging"},
+	}}
+	private := &Bucket{Name: "acme-private", Region: "us-east-1", Configuration: &BucketConfiguration{
+		Encryption: &s3types.ServerSideEncryptionConfiguration{Rules: []s3types.ServerSideEncryptionRule{{
+			ApplyServerSideEncryptionByDefault: &s3types.ServerSideEncryptionByDefault{SSEAlgorithm: s3types.ServerSideEncryptionAes256},
+		}}},
+	}}
+
+	rules, err := Rules(helpers.RuleProfile{Enabled: []string{"S3.2", "S3.4", "S3.9"}})
+	if err != nil {
+		t.Fatalf("Rules() returned an error: %s", err)
+	}
+	rules = append(rules, namingRule{})
+
+	results := Evaluate(rules, []*Bucket{public, private}, nil)
+	expected := []struct {
+		id         string
+		evaluated  int
+		violations []string
+	}{
+		{id: "S3.2", evaluated: 2, violations: []string{"website: public read via policy (statement PublicRead)", "website: public read via ACL"}},
+		{id: "S3.4", evaluated: 2, violations: []string{"website: no default encryption"}},
+		{id: "S3.9", evaluated: 1, violations: []string{"acme-private: no server access logging"}},
+		{id: "CUSTOM.1", evaluated: 2, violations: []string{"website: name without the acme- prefix"}},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Evaluate() returned %v results, want %v", len(results), len(expected))
+	}
+	for i, result := range results {
+		if result.ID != expected[i].id || result.EvaluatedBuckets != expected[i].evaluated {
+			t.Errorf("Evaluate()[%v] == %v on %v buckets, want %v on %v buckets", i, result.ID, result.EvaluatedBuckets, expected[i].id, expected[i].evaluated)
+		}
+		if len(result.Violations) != len(expected[i].violations) {
+			t.Errorf("Evaluate()[%v] returned %v violations, want %v: %v", i, len(result.Violations), len(expected[i].violations), result.Violations)
+			continue
+		}
+		for j, violation := range result.Violations {
+			if message := violation.Bucket + ": " + violation.Message; message != expected[i].violations[j] {
+				t.Errorf("Evaluate()[%v] violation %v == %v, want %v", i, j, message, expected[i].violations[j])
+			}
+		}
+	}
+}
+
+func TestEvaluateCompliantBucket(t *testing.T) {
+	compliant := &Bucket{Name: "acme-archive", Region: "us-east-1", Configuration: &BucketConfiguration{
+		Policy:       aws.String(`{"Statement": [{"Sid": "Backup", "Effect": "Allow", "Principal": {"AWS": "111122223333"}, "Action": "s3:GetObject", "Resource": "*"}]}`),
+		PolicyStatus: &s3types.PolicyStatus{IsPublic: aws.Bool(false)},
+		Versioning:   s3types.BucketVersioningStatusEnabled,
+		MFADelete:    s3types.MFADeleteStatusEnabled,
+		HasLifecycle: true,
+	}, LifecycleRules: []s3types.LifecycleRule{{
+		Status:                      s3types.ExpirationStatusEnabled,
+		NoncurrentVersionExpiration: &s3types.NoncurrentVersionExpiration{NoncurrentDays: aws.Int32(30)},
+	}}}
+	unversioned := &Bucket{Name: "acme-logs", Region: "us-east-1", Configuration: &BucketConfiguration{}}
+
+	rules, err := Rules(helpers.RuleProfile{Enabled: []string{"S3.6", "POLICY.1", "S3.10", "CIS-2.1.2"}})
+	if err != nil {
+		t.Fatalf("Rules() returned an error: %s", err)
+	}
+
+	// Every rule is evaluated on both buckets, only the unversioned one misses MFA delete
+	results := Evaluate(rules, []*Bucket{compliant, unversioned}, []string{"111122223333"})
+	violations := map[string][]string{"CIS-2.1.2": {"acme-logs: MFA delete disabled"}}
+	if len(results) != len(rules) {
+		t.Fatalf("Evaluate() returned %v results, want %v", len(results), len(rules))
+	}
+	for _, result := range results {
+		if result.EvaluatedBuckets != 2 {
+			t.Errorf("Evaluate() evaluated %v on %v buckets, want 2", result.ID, result.EvaluatedBuckets)
+		}
+		if len(result.Violations) != len(violations[result.ID]) {
+			t.Errorf("Evaluate() returned %v violations for %v, want %v: %v", len(result.Violations), result.ID, len(violations[result.ID]), result.Violations)
+			continue
+		}
+		for j, violation := range result.Violations {
+			if message := violation.Bucket + ": " + violation.Message; message != violations[result.ID][j] {
+				t.Errorf("Evaluate() %v violation %v == %v, want %v", result.ID, j, message, violations[result.ID][j])
+			}
+		}
+	}
+}
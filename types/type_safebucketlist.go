@@ -4,5 +4,35 @@ import "sync"
 
 type SafeBucketList struct {
 	Buckets *[]*Bucket
-	Lock    sync.Mutex
+	// Errors that are not tied to a single bucket (e.g. listing the buckets themselves)
+	Errors []error
+	// Objects of every bucket by ETag and size, only set when looking for duplicates
+	Duplicates *DuplicateIndex
+	Lock       sync.Mutex
+}
+
+func (l *SafeBucketList) AddBucket(bucket *Bucket) {
+	l.Lock.Lock()
+	*l.Buckets = append(*l.Buckets, bucket)
+	l.Lock.Unlock()
+}
+
+func (l *SafeBucketList) AddError(err error) {
+	l.Lock.Lock()
+	l.Errors = append(l.Errors, err)
+	l.Lock.Unlock()
+}
+
+func (l *SafeBucketList) FailedBuckets() []*Bucket {
+	failed := []*Bucket{}
+	for _, bucket := range *l.Buckets {
+		if bucket.HasErrors() {
+			failed = append(failed, bucket)
+		}
+	}
+	return failed
+}
+
+func (l *SafeBucketList) HasErrors() bool {
+	return len(l.Errors) > 0 || len(l.FailedBuckets()) > 0
 }
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// SafeClientPool lazily creates one S3 client per region and shares them across goroutines
+type SafeClientPool struct {
+	Config  aws.Config
+	Clients map[string]*s3.Client
+	Lock    sync.Mutex
+}
+
+func NewSafeClientPool(cfg aws.Config) *SafeClientPool {
+	return &SafeClientPool{
+		Config:  cfg,
+		Clients: map[string]*s3.Client{},
+		Lock:    sync.Mutex{},
+	}
+}
+
+// GetClient returns the client for the given region, creating it on first use.
+// An empty region falls back to the region of the default config.
+func (p *SafeClientPool) GetClient(region string) *s3.Client {
+	if region == "" {
+		region = p.Config.Region
+	}
+
+	p.Lock.Lock()
+	defer p.Lock.Unlock()
+
+	if client, ok := p.Clients[region]; ok {
+		return client
+	}
+
+	client := s3.NewFromConfig(p.Config, func(o *s3.Options) {
+		o.Region = region
+	})
+	p.Clients[region] = client
+
+	return client
+}
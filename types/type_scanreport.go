@@ -0,0 +1,356 @@
+package types
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/padeshaies/s3-bucket-analysis-tool/helpers"
+)
+
+// ScanReport is the machine-readable view of a whole scan
+type ScanReport struct {
+	StartTime string         `json:"startTime"`
+	EndTime   string         `json:"endTime"`
+	Account   string         `json:"account"`
+	Buckets   []BucketReport `json:"buckets"`
+	Errors    []string       `json:"errors"`
+
+	Duplicates []DuplicateSet `json:"duplicates,omitempty"`
+}
+
+type BucketReport struct {
+	Name                   string             `json:"name"`
+	Region                 string             `json:"region"`
+	CreationDate           string             `json:"creationDate"`
+	MostRecentModifiedDate string             `json:"mostRecentModifiedDate"`
+	StorageTypes           []string           `json:"storageTypes"`
+	ObjectsNumber          map[string]int     `json:"objectsNumber"`
+	ObjectsSize            map[string]int     `json:"objectsSize"`
+	TotalObjectNumber      int                `json:"totalObjectNumber"`
+	TotalSize              int                `json:"totalSize"`
+	Costs                  map[string]float64 `json:"costs"`
+	BillableSize           map[string]int     `json:"billableSize"`
+	OverheadCosts          map[string]float64 `json:"overheadCosts"`
+	// One-time charge if the objects younger than the minimum storage duration were deleted now
+	MinimumDurationCosts map[string]float64 `json:"minimumDurationCosts"`
+	TotalCost            float64            `json:"totalCost"`
+	CostError            string             `json:"costError,omitempty"`
+	Errors               []string           `json:"errors"`
+
+	Requests         map[string]helpers.RequestCounts `json:"requests,omitempty"`
+	RequestCosts     map[string]float64               `json:"requestCosts,omitempty"`
+	TotalRequestCost float64                          `json:"totalRequestCost"`
+	RequestCostError string                           `json:"requestCostError,omitempty"`
+
+	IntelligentTiering *IntelligentTieringReport `json:"intelligentTiering,omitempty"`
+
+	SizeHistograms map[string][]HistogramRangeReport `json:"sizeHistograms"`
+	AgeHistograms  map[string][]HistogramRangeReport `json:"ageHistograms"`
+	StaleSize      map[string]int                    `json:"staleSize"`
+	Stale          bool                              `json:"stale"`
+
+	// Only set when scanning versions, TotalCost includes the noncurrent costs
+	NoncurrentObjectsNumber map[string]int     `json:"noncurrentObjectsNumber,omitempty"`
+	NoncurrentObjectsSize   map[string]int     `json:"noncurrentObjectsSize,omitempty"`
+	NoncurrentCosts         map[string]float64 `json:"noncurrentCosts,omitempty"`
+	TotalNoncurrentCost     float64            `json:"totalNoncurrentCost,omitempty"`
+	DeleteMarkers           int                `json:"deleteMarkers,omitempty"`
+
+	// Only set when the multipart uploads were listed, TotalCost includes their cost
+	MultipartUploads                      []MultipartUploadReport `json:"multipartUploads,omitempty"`
+	MultipartUploadsCost                  float64                 `json:"multipartUploadsCost,omitempty"`
+	MultipartUploadsCosts                 map[string]float64      `json:"multipartUploadsCosts,omitempty"`
+	MissingAbortIncompleteMultipartUpload bool                    `json:"missingAbortIncompleteMultipartUpload,omitempty"`
+
+	Prefixes []PrefixReport `json:"prefixes,omitempty"`
+
+	LargestObjects []ObjectReport `json:"largestObjects,omitempty"`
+	OldestObjects  []ObjectReport `json:"oldestObjects,omitempty"`
+
+	Recommendations     []Recommendation `json:"recommendations,omitempty"`
+	RecommendationError string           `json:"recommendationError,omitempty"`
+}
+
+// IntelligentTieringReport holds the assumptions behind the Intelligent-Tiering cost
+type IntelligentTieringReport struct {
+	MonitoredObjects int                `json:"monitoredObjects"`
+	Split            map[string]float64 `json:"split"`
+	Inferred         bool               `json:"inferred"`
+}
+
+type ObjectReport struct {
+	Key          string `json:"key"`
+	StorageType  string `json:"storageType"`
+	Size         int    `json:"size"`
+	LastModified string `json:"lastModified"`
+	ETag         string `json:"etag"`
+}
+
+// NewScanReport builds the report of a finished scan, errors holds the failures that are not
+// tied to a bucket. Dates are formatted in RFC 3339 using the display timezone.
+func NewScanReport(startTime, endTime time.Time, account string, buckets []*Bucket, errors []error, displaySettings DisplaySettings) ScanReport {
+	report := ScanReport{
+		StartTime: formatDate(startTime, displaySettings),
+		EndTime:   formatDate(endTime, displaySettings),
+		Account:   account,
+		Buckets:   []BucketReport{},
+		Errors:    errorStrings(errors),
+	}
+
+	for _, bucket := range buckets {
+		report.Buckets = append(report.Buckets, bucket.Report(displaySettings))
+	}
+
+	return report
+}
+
+func (r ScanReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV flattens the report into one row per bucket per storage type. Buckets without
+// objects still get a single row with an empty storage type so they are not lost.
+func (r ScanReport) WriteCSV(w io.Writer, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	header := []string{"bucket", "region", "storage_type", "object_count", "bytes", "monthly_cost", "creation_date", "most_recent_modified_date", "monthly_request_cost", "billable_bytes", "monthly_overhead_cost", "minimum_duration_cost"}
+	for _, r := range helpers.SizeHistogramRanges {
+		header = append(header, "objects_"+r.Key)
+	}
+	for _, r := range helpers.AgeHistogramRanges {
+		header = append(header, "bytes_age_"+r.Key)
+	}
+	header = append(header, "bytes_stale", "noncurrent_object_count", "noncurrent_bytes", "monthly_noncurrent_cost", "monthly_multipart_uploads_cost")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, bucket := range r.Buckets {
+		storageTypes := slices.Clone(bucket.StorageTypes)
+		for storageType := range bucket.NoncurrentObjectsSize {
+			if !slices.Contains(storageTypes, storageType) {
+				storageTypes = append(storageTypes, storageType)
+			}
+		}
+		for storageType := range bucket.MultipartUploadsCosts {
+			if !slices.Contains(storageTypes, storageType) {
+				storageTypes = append(storageTypes, storageType)
+			}
+		}
+		slices.Sort(storageTypes)
+		if len(storageTypes) == 0 {
+			storageTypes = []string{""}
+		}
+
+		for _, storageType := range storageTypes {
+			// Leave the cost empty rather than writing a misleading 0 when it could not be calculated
+			cost, billableBytes, overheadCost, minimumDurationCost, noncurrentCost := "", "", "", "", ""
+			if bucket.CostError == "" {
+				noncurrentCost = strconv.FormatFloat(bucket.NoncurrentCosts[storageType], 'f', 2, 64)
+				cost = strconv.FormatFloat(bucket.Costs[storageType], 'f', 2, 64)
+				billableBytes = strconv.Itoa(bucket.BillableSize[storageType])
+				overheadCost = strconv.FormatFloat(bucket.OverheadCosts[storageType], 'f', 2, 64)
+				minimumDurationCost = strconv.FormatFloat(bucket.MinimumDurationCosts[storageType], 'f', 2, 64)
+			}
+
+			// Empty when the uploads were not listed or could not be priced
+			multipartUploadsCost := ""
+			if bucket.MultipartUploadsCosts != nil {
+				multipartUploadsCost = strconv.FormatFloat(bucket.MultipartUploadsCosts[storageType], 'f', 2, 64)
+			}
+
+			requestCost := ""
+			if bucket.RequestCostError == "" {
+				requestCost = strconv.FormatFloat(bucket.RequestCosts[storageType], 'f', 2, 64)
+			}
+
+			row := []string{
+				bucket.Name,
+				bucket.Region,
+				storageType,
+				strconv.Itoa(bucket.ObjectsNumber[storageType]),
+				strconv.Itoa(bucket.ObjectsSize[storageType]),
+				cost,
+				bucket.CreationDate,
+				bucket.MostRecentModifiedDate,
+				requestCost,
+				billableBytes,
+				overheadCost,
+				minimumDurationCost,
+			}
+			for i := range helpers.SizeHistogramRanges {
+				number := 0
+				if histogram, ok := bucket.SizeHistograms[storageType]; ok {
+					number = histogram[i].Objects
+				}
+				row = append(row, strconv.Itoa(number))
+			}
+			for i := range helpers.AgeHistogramRanges {
+				size := 0
+				if histogram, ok := bucket.AgeHistograms[storageType]; ok {
+					size = histogram[i].Bytes
+				}
+				row = append(row, strconv.Itoa(size))
+			}
+			row = append(row, strconv.Itoa(bucket.StaleSize[storageType]), strconv.Itoa(bucket.NoncurrentObjectsNumber[storageType]), strconv.Itoa(bucket.NoncurrentObjectsSize[storageType]), noncurrentCost, multipartUploadsCost)
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (b *Bucket) Report(displaySettings DisplaySettings) BucketReport {
+	pricing := displaySettings.Pricing
+	report := BucketReport{
+		Name:                   b.Name,
+		Region:                 b.Region,
+		CreationDate:           formatDate(b.CreationDate, displaySettings),
+		MostRecentModifiedDate: formatDate(b.MostRecentModifiedDate, displaySettings),
+		StorageTypes:           b.StorageTypes,
+		ObjectsNumber:          b.ObjectsNumber,
+		ObjectsSize:            b.ObjectsSize,
+		TotalObjectNumber:      b.TotalObjectNumber(),
+		TotalSize:              b.TotalSize(),
+		Costs:                  map[string]float64{},
+		BillableSize:           map[string]int{},
+		OverheadCosts:          map[string]float64{},
+		MinimumDurationCosts:   map[string]float64{},
+		Errors:                 errorStrings(b.Errors),
+		SizeHistograms:         map[string][]HistogramRangeReport{},
+		AgeHistograms:          map[string][]HistogramRangeReport{},
+		StaleSize:              b.StaleSize,
+		Stale:                  b.IsStale(),
+	}
+
+	for storageType, histogram := range b.SizeHistograms {
+		report.SizeHistograms[storageType] = histogram.Report(helpers.SizeHistogramRanges)
+	}
+	for storageType, histogram := range b.AgeHistograms {
+		report.AgeHistograms[storageType] = histogram.Report(helpers.AgeHistogramRanges)
+	}
+
+	if slices.Contains(b.StorageTypes, "INTELLIGENT_TIERING") {
+		split, inferred := b.IntelligentTieringSplit(pricing)
+		report.IntelligentTiering = &IntelligentTieringReport{
+			MonitoredObjects: b.MonitoredObjectsNumber(),
+			Split:            split,
+			Inferred:         inferred,
+		}
+	}
+
+	if b.TotalNoncurrentObjectNumber() > 0 || b.DeleteMarkers > 0 {
+		report.NoncurrentObjectsNumber = b.NoncurrentObjectsNumber
+		report.NoncurrentObjectsSize = b.NoncurrentObjectsSize
+		report.DeleteMarkers = b.DeleteMarkers
+
+		noncurrentCosts, err := b.NoncurrentCosts(pricing)
+		if err == nil {
+			report.NoncurrentCosts = map[string]float64{}
+			for storageType, noncurrentCost := range noncurrentCosts {
+				report.NoncurrentCosts[storageType] = noncurrentCost.Cost
+			}
+			report.TotalNoncurrentCost, _ = b.TotalNoncurrentCost(pricing)
+		}
+	}
+
+	if b.MultipartUploadsScanned {
+		for _, upload := range b.MultipartUploads {
+			report.MultipartUploads = append(report.MultipartUploads, upload.Report(displaySettings))
+		}
+		if costs, err := b.MultipartUploadsCostByStorageType(pricing); err == nil {
+			report.MultipartUploadsCosts = costs
+			report.MultipartUploadsCost, _ = b.MultipartUploadsCost(pricing)
+		}
+		report.MissingAbortIncompleteMultipartUpload = b.MissesAbortIncompleteMultipartUploadRule()
+	}
+
+	storageCosts, err := b.StorageCosts(pricing)
+	if err != nil {
+		report.CostError = err.Error()
+	} else {
+		for storageType, storageCost := range storageCosts {
+			report.Costs[storageType] = storageCost.Cost
+			report.BillableSize[storageType] = storageCost.BillableBytes + storageCost.StandardMetadataBytes
+			report.OverheadCosts[storageType] = storageCost.OverheadCost
+			report.MinimumDurationCosts[storageType] = storageCost.MinimumDurationCost
+		}
+		if report.TotalCost, err = b.TotalCost(pricing); err != nil {
+			report.CostError = err.Error()
+		}
+	}
+
+	if len(b.Prefixes.Children) > 0 {
+		costs, err := b.CostByStorageType(pricing)
+		if err != nil {
+			costs = nil
+		}
+		report.Prefixes = b.Prefixes.Report(costs, b.ObjectsSize)
+	}
+
+	for _, object := range b.LargestObjects.Sorted() {
+		report.LargestObjects = append(report.LargestObjects, objectReport(object, displaySettings))
+	}
+	for _, object := range b.OldestObjects.Sorted() {
+		report.OldestObjects = append(report.OldestObjects, objectReport(object, displaySettings))
+	}
+
+	if displaySettings.Recommendations {
+		recommendations, err := b.Recommendations(pricing)
+		if err != nil {
+			report.RecommendationError = err.Error()
+		} else {
+			report.Recommendations = recommendations
+		}
+	}
+
+	if len(b.Requests) > 0 {
+		report.Requests = b.RequestCounts()
+
+		requestCosts, err := b.RequestsCostByStorageType(pricing)
+		if err != nil {
+			report.RequestCostError = err.Error()
+		} else {
+			report.RequestCosts = requestCosts
+			report.TotalRequestCost, _ = b.TotalRequestsCost(pricing)
+		}
+	}
+
+	return report
+}
+
+func objectReport(object ObjectRecord, displaySettings DisplaySettings) ObjectReport {
+	return ObjectReport{
+		Key:          object.Key,
+		StorageType:  object.StorageType,
+		Size:         object.Size,
+		LastModified: formatDate(object.LastModified, displaySettings),
+		ETag:         object.ETag,
+	}
+}
+
+func formatDate(date time.Time, displaySettings DisplaySettings) string {
+	// Empty buckets have no modified date
+	if date.IsZero() {
+		return ""
+	}
+	return date.In(displaySettings.Timezone).Format(time.RFC3339)
+}
+
+func errorStrings(errors []error) []string {
+	result := []string{}
+	for _, err := range errors {
+		result = append(result, err.Error())
+	}
+	return result
+}
@@ -0,0 +1,169 @@
+package types
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// goldenScanReport has a bucket whose name needs quoting, with multipart uploads in a storage type
+// it has no objects in, and a bucket that could not be priced
+func goldenScanReport() ScanReport {
+	return ScanReport{
+		StartTime: "2025-01-02T03:04:05Z",
+		EndTime:   "2025-01-02T03:05:05Z",
+		Account:   "123456789012",
+		Buckets: []BucketReport{
+			{
+				Name:                   `logs, "prod"`,
+				Region:                 "us-east-1",
+				CreationDate:           "2024-01-01T00:00:00Z",
+				MostRecentModifiedDate: "2025-01-01T00:00:00Z",
+				StorageTypes:           []string{"STANDARD_IA", "STANDARD"},
+				ObjectsNumber:          map[string]int{"STANDARD": 2, "STANDARD_IA": 1},
+				ObjectsSize:            map[string]int{"STANDARD": 2048, "STANDARD_IA": 200000},
+				TotalObjectNumber:      3,
+				TotalSize:              202048,
+				Costs:                  map[string]float64{"STANDARD": 0.01, "STANDARD_IA": 0.02},
+				BillableSize:           map[string]int{"STANDARD": 2048, "STANDARD_IA": 200000},
+				OverheadCosts:          map[string]float64{},
+				MinimumDurationCosts:   map[string]float64{"STANDARD_IA": 0.01},
+				TotalCost:              0.03,
+				Errors:                 []string{},
+				MultipartUploadsCost:   0.05,
+				MultipartUploadsCosts:  map[string]float64{"GLACIER": 0.05},
+			},
+			{
+				Name:             "empty",
+				Region:           "eu-west-3",
+				CostError:        "no pricing",
+				RequestCostError: "no pricing",
+				Errors:           []string{"listing objects: access denied"},
+			},
+		},
+		Errors: []string{},
+	}
+}
+
+func TestScanReportWriteCSV(t *testing.T) {
+	header := []string{"bucket", "region", "storage_type", "object_count", "bytes", "monthly_cost", "creation_date", "most_recent_modified_date", "monthly_request_cost", "billable_bytes", "monthly_overhead_cost", "minimum_duration_cost",
+		"objects_0_1kb", "objects_1kb_128kb", "objects_128kb_1mb", "objects_1mb_16mb", "objects_16mb_128mb", "objects_128mb_1gb", "objects_1gb_5gb", "objects_5gb_plus",
+		"bytes_age_0_30d", "bytes_age_30d_90d", "bytes_age_90d_180d", "bytes_age_180d_1y", "bytes_age_1y_3y", "bytes_age_3y_plus",
+		"bytes_stale", "noncurrent_object_count", "noncurrent_bytes", "monthly_noncurrent_cost", "monthly_multipart_uploads_cost"}
+	// 8 size ranges, 6 age ranges, the stale bytes and the noncurrent objects and bytes
+	zeros := slices.Repeat([]string{"0"}, 17)
+
+	// The storage types are sorted, the name is quoted with its quotes doubled, and the costs of
+	// the bucket that could not be priced are left empty
+	rows := [][]string{
+		header,
+		append(append([]string{`"logs, ""prod"""`, "us-east-1", "GLACIER", "0", "0", "0.00", "2024-01-01T00:00:00Z", "2025-01-01T00:00:00Z", "0.00", "0", "0.00", "0.00"}, zeros...), "0.00", "0.05"),
+		append(append([]string{`"logs, ""prod"""`, "us-east-1", "STANDARD", "2", "2048", "0.01", "2024-01-01T00:00:00Z", "2025-01-01T00:00:00Z", "0.00", "2048", "0.00", "0.00"}, zeros...), "0.00", "0.00"),
+		append(append([]string{`"logs, ""prod"""`, "us-east-1", "STANDARD_IA", "1", "200000", "0.02", "2024-01-01T00:00:00Z", "2025-01-01T00:00:00Z", "0.00", "200000", "0.00", "0.01"}, zeros...), "0.00", "0.00"),
+		append(append([]string{"empty", "eu-west-3", "", "0", "0", "", "", "", "", "", "", ""}, zeros...), "", ""),
+	}
+
+	for _, delimiter := range []string{",", "\t"} {
+		expected := ""
+		for _, row := range rows {
+			expected += strings.Join(row, delimiter) + "\n"
+		}
+
+		output := &strings.Builder{}
+		if err := goldenScanReport().WriteCSV(output, rune(delimiter[0])); err != nil {
+			t.Fatalf("WriteCSV(%q) returned an error: %s", delimiter, err)
+		}
+		if output.String() != expected {
+			t.Errorf("WriteCSV(%q) ==\n%v\nwant\n%v", delimiter, output.String(), expected)
+		}
+	}
+}
+
+func TestScanReportWriteJSON(t *testing.T) {
+	expected := `{
+  "startTime": "2025-01-02T03:04:05Z",
+  "endTime": "2025-01-02T03:05:05Z",
+  "account": "123456789012",
+  "buckets": [
+    {
+      "name": "logs, \"prod\"",
+      "region": "us-east-1",
+      "creationDate": "2024-01-01T00:00:00Z",
+      "mostRecentModifiedDate": "2025-01-01T00:00:00Z",
+      "storageTypes": [
+        "STANDARD_IA",
+        "STANDARD"
+      ],
+      "objectsNumber": {
+        "STANDARD": 2,
+        "STANDARD_IA": 1
+      },
+      "objectsSize": {
+        "STANDARD": 2048,
+        "STANDARD_IA": 200000
+      },
+      "totalObjectNumber": 3,
+      "totalSize": 202048,
+      "costs": {
+        "STANDARD": 0.01,
+        "STANDARD_IA": 0.02
+      },
+      "billableSize": {
+        "STANDARD": 2048,
+        "STANDARD_IA": 200000
+      },
+      "overheadCosts": {},
+      "minimumDurationCosts": {
+        "STANDARD_IA": 0.01
+      },
+      "totalCost": 0.03,
+      "errors": [],
+      "totalRequestCost": 0,
+      "sizeHistograms": null,
+      "ageHistograms": null,
+      "staleSize": null,
+      "stale": false,
+      "multipartUploadsCost": 0.05,
+      "multipartUploadsCosts": {
+        "GLACIER": 0.05
+      }
+    },
+    {
+      "name": "empty",
+      "region": "eu-west-3",
+      "creationDate": "",
+      "mostRecentModifiedDate": "",
+      "storageTypes": null,
+      "objectsNumber": null,
+      "objectsSize": null,
+      "totalObjectNumber": 0,
+      "totalSize": 0,
+      "costs": null,
+      "billableSize": null,
+      "overheadCosts": null,
+      "minimumDurationCosts": null,
+      "totalCost": 0,
+      "costError": "no pricing",
+      "errors": [
+        "listing objects: access denied"
+      ],
+      "totalRequestCost": 0,
+      "requestCostError": "no pricing",
+      "sizeHistograms": null,
+      "ageHistograms": null,
+      "staleSize": null,
+      "stale": false
+    }
+  ],
+  "errors": []
+}
+`
+
+	output := &strings.Builder{}
+	if err := goldenScanReport().WriteJSON(output); err != nil {
+		t.Fatalf("WriteJSON() returned an error: %s", err)
+	}
+	if output.String() != expected {
+		t.Errorf("WriteJSON() ==\n%v\nwant\n%v", output.String(), expected)
+	}
+}
@@ -0,0 +1,40 @@
+package types
+
+type ScanSettings struct {
+	// Exit with a non-zero code when some buckets could only be partially scanned
+	FailOnPartial bool
+
+	// Maximum number of buckets analyzed at the same time
+	BucketConcurrency int
+	// Maximum number of object pages analyzed at the same time for a single bucket
+	PageConcurrency int
+
+	// List every version of the objects instead of the current ones only
+	ListVersions bool
+
+	// List the incomplete multipart uploads and the lifecycle rules of each bucket
+	ListMultipartUploads bool
+
+	// Keep every object on the buckets, needed by the lifecycle simulation
+	RecordObjects bool
+	// Look for the objects stored more than once across the scanned buckets
+	FindDuplicates bool
+	// Collect the object ages per top-level prefix used by the recommendations
+	Recommendations bool
+
+	// Objects not modified for more than StaleDays are stale, helpers.DefaultStaleDays when 0
+	StaleDays int
+	// Number of largest and oldest objects kept per bucket, 0 disables them
+	TopObjects int
+	// Number of prefix levels aggregated under each bucket, 0 disables the prefix tree
+	PrefixDepth int
+	// Delimiter separating the levels of the keys, "/" when empty
+	PrefixDelimiter string
+
+	// Accounts the bucket policies may grant access to without being reported, usually the
+	// scanned account and its organization
+	TrustedAccounts []string
+
+	// Fetch the security related settings of each bucket instead of listing its objects
+	Audit bool
+}